@@ -0,0 +1,156 @@
+// budgetdeque.go, jpad 2026
+
+/*
+Package budgetdeque implements a double ended queue that is limited by the
+accumulated size of its items rather than by their number. The size of each
+item is determined by a user supplied SizeFunc. When a push takes the deque
+over its byte budget, items are evicted from the front or from the back until
+it fits again, and handed to an eviction callback, which can drop them or pass
+them on to a spill sink, like a diskdeque.Deque.
+*/
+package budgetdeque
+
+import (
+	"github.com/notnot/container/deque"
+	"github.com/notnot/container/deque_int"
+)
+
+// SizeFunc returns the size of an item in bytes.
+type SizeFunc func(item interface{}) int
+
+// EvictFunc receives the items evicted to bring the deque within its budget.
+type EvictFunc func(item interface{})
+
+// Policy selects the end of the deque that items are evicted from.
+type Policy int
+
+const (
+	// DropOldest evicts items from the front of the deque.
+	DropOldest Policy = iota
+	// DropNewest evicts items from the back of the deque, which can be the
+	// item that was just pushed.
+	DropNewest
+)
+
+// Options configure a deque.
+type Options struct {
+	Budget   int       // maximum accumulated size of the items, in bytes
+	SizeFunc SizeFunc  // item size, required
+	Policy   Policy    // eviction end, default DropOldest
+	OnEvict  EvictFunc // receives the evicted items, may be nil
+}
+
+//// Deque /////////////////////////////////////////////////////////////////////
+
+// Deque is a double ended queue with a byte budget.
+type Deque struct {
+	opts  Options
+	items *deque.Deque
+	sizes *deque_int.Deque // item sizes, parallel to items
+	bytes int
+}
+
+// New returns a pointer to an empty deque configured by opts.
+func New(opts Options) *Deque {
+	if opts.SizeFunc == nil {
+		panic("budgetdeque: Options.SizeFunc is nil")
+	}
+	return &Deque{
+		opts:  opts,
+		items: deque.New(),
+		sizes: deque_int.New(),
+	}
+}
+
+// PushFront adds an item to the front of the deque, then evicts items as long
+// as the deque exceeds its budget.
+func (d *Deque) PushFront(item interface{}) {
+	size := d.opts.SizeFunc(item)
+	d.items.PushFront(item)
+	d.sizes.PushFront(size)
+	d.bytes += size
+	d.evict()
+}
+
+// PushBack adds an item to the back of the deque, then evicts items as long
+// as the deque exceeds its budget.
+func (d *Deque) PushBack(item interface{}) {
+	size := d.opts.SizeFunc(item)
+	d.items.PushBack(item)
+	d.sizes.PushBack(size)
+	d.bytes += size
+	d.evict()
+}
+
+// PopFront removes and returns the item from the front of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) PopFront() interface{} {
+	if d.items.Size() == 0 {
+		return nil
+	}
+	d.bytes -= d.sizes.PopFront()
+	return d.items.PopFront()
+}
+
+// PopBack removes and returns the item from the back of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) PopBack() interface{} {
+	if d.items.Size() == 0 {
+		return nil
+	}
+	d.bytes -= d.sizes.PopBack()
+	return d.items.PopBack()
+}
+
+// FrontItem returns the item at the front of the deque.
+func (d *Deque) FrontItem() interface{} {
+	return d.items.FrontItem()
+}
+
+// BackItem returns the item at the back of the deque.
+func (d *Deque) BackItem() interface{} {
+	return d.items.BackItem()
+}
+
+// Size returns the number of items in the deque.
+func (d *Deque) Size() int {
+	return d.items.Size()
+}
+
+// Bytes returns the accumulated size of the items in the deque.
+func (d *Deque) Bytes() int {
+	return d.bytes
+}
+
+// Budget returns the byte budget of the deque.
+func (d *Deque) Budget() int {
+	return d.opts.Budget
+}
+
+// SetBudget changes the byte budget of the deque, evicting items if the deque
+// exceeds the new budget.
+func (d *Deque) SetBudget(budget int) {
+	d.opts.Budget = budget
+	d.evict()
+}
+
+// Clear removes all items from the deque, without evicting them.
+func (d *Deque) Clear() {
+	d.items.Clear()
+	d.sizes.Clear()
+	d.bytes = 0
+}
+
+func (d *Deque) evict() {
+	for d.bytes > d.opts.Budget && d.items.Size() > 0 {
+		var item interface{}
+		if d.opts.Policy == DropNewest {
+			item = d.PopBack()
+		} else {
+			item = d.PopFront()
+		}
+		if d.opts.OnEvict != nil {
+			d.opts.OnEvict(item)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// budgetdeque_test.go, jpad 2026
+
+package budgetdeque_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/notnot/container/budgetdeque"
+	"github.com/notnot/container/deque"
+)
+
+func byteLen(item interface{}) int {
+	return len(item.(string))
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestEmpty(t *testing.T) {
+	d := budgetdeque.New(budgetdeque.Options{Budget: 10, SizeFunc: byteLen})
+
+	if d.PopFront() != nil || d.PopBack() != nil {
+		t.Errorf("got: item, want: <nil>")
+	}
+	if d.Size() != 0 || d.Bytes() != 0 {
+		t.Errorf("got: %d items, %d bytes, want: none", d.Size(), d.Bytes())
+	}
+}
+
+func TestBytes(t *testing.T) {
+	d := budgetdeque.New(budgetdeque.Options{Budget: 100, SizeFunc: byteLen})
+
+	d.PushBack("abc")
+	d.PushFront("de")
+	d.PushBack("f")
+	if d.Bytes() != 6 || d.Size() != 3 {
+		t.Errorf("got: %d bytes, %d items, want: 6 bytes, 3 items", d.Bytes(), d.Size())
+	}
+	if item := d.PopBack(); item != "f" {
+		t.Errorf("got: %v, want: f", item)
+	}
+	if item := d.PopFront(); item != "de" {
+		t.Errorf("got: %v, want: de", item)
+	}
+	if d.Bytes() != 3 {
+		t.Errorf("got: %d, want: 3", d.Bytes())
+	}
+	d.Clear()
+	if d.Bytes() != 0 {
+		t.Errorf("got: %d, want: 0", d.Bytes())
+	}
+}
+
+func TestDropOldest(t *testing.T) {
+	evicted := []interface{}{}
+	d := budgetdeque.New(budgetdeque.Options{
+		Budget:   10,
+		SizeFunc: byteLen,
+		OnEvict:  func(item interface{}) { evicted = append(evicted, item) },
+	})
+
+	for _, item := range []string{"aaaa", "bbbb", "cc", "dddd"} {
+		d.PushBack(item)
+	}
+	if fmt.Sprint(evicted) != "[aaaa]" {
+		t.Errorf("got: %v, want: [aaaa]", evicted)
+	}
+	if d.Bytes() != 10 || d.FrontItem() != "bbbb" {
+		t.Errorf("got: %d bytes from %v, want: 10 bytes from bbbb", d.Bytes(), d.FrontItem())
+	}
+
+	// an item over budget on its own doesn't stay
+	d.PushBack("xxxxxxxxxxx")
+	if d.Size() != 0 || d.Bytes() != 0 {
+		t.Errorf("got: %d items, %d bytes, want: none", d.Size(), d.Bytes())
+	}
+	if len(evicted) != 5 {
+		t.Errorf("got: %d, want: 5", len(evicted))
+	}
+}
+
+func TestDropNewest(t *testing.T) {
+	evicted := []interface{}{}
+	d := budgetdeque.New(budgetdeque.Options{
+		Budget:   10,
+		SizeFunc: byteLen,
+		Policy:   budgetdeque.DropNewest,
+		OnEvict:  func(item interface{}) { evicted = append(evicted, item) },
+	})
+
+	for _, item := range []string{"aaaa", "bbbb", "cc", "dddd"} {
+		d.PushBack(item)
+	}
+	if fmt.Sprint(evicted) != "[dddd]" {
+		t.Errorf("got: %v, want: [dddd]", evicted)
+	}
+
+	d.SetBudget(5)
+	if fmt.Sprint(evicted) != "[dddd cc bbbb]" {
+		t.Errorf("got: %v, want: [dddd cc bbbb]", evicted)
+	}
+	if d.Budget() != 5 || d.Bytes() != 4 {
+		t.Errorf("got: %d of %d bytes, want: 4 of 5 bytes", d.Bytes(), d.Budget())
+	}
+}
+
+func TestSpill(t *testing.T) {
+	spill := deque.New()
+	d := budgetdeque.New(budgetdeque.Options{
+		Budget:   8,
+		SizeFunc: byteLen,
+		OnEvict:  spill.PushBack,
+	})
+
+	for i := 0; i < 100; i++ {
+		d.PushBack(fmt.Sprintf("%02d", i))
+	}
+	if d.Size() != 4 || spill.Size() != 96 {
+		t.Errorf("got: %d kept, %d spilled, want: 4 kept, 96 spilled", d.Size(), spill.Size())
+	}
+	if spill.FrontItem() != "00" || spill.BackItem() != "95" || d.FrontItem() != "96" {
+		t.Errorf("got: spill %v..%v, front %v, want: spill 00..95, front 96",
+			spill.FrontItem(), spill.BackItem(), d.FrontItem())
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleDeque() {
+	d := budgetdeque.New(budgetdeque.Options{
+		Budget:   16,
+		SizeFunc: func(item interface{}) int { return len(item.([]byte)) },
+		OnEvict: func(item interface{}) {
+			fmt.Printf("evicted %q\n", item)
+		},
+	})
+
+	d.PushBack([]byte("hello, "))
+	d.PushBack([]byte("world"))
+	d.PushBack([]byte("!!!!!"))
+	fmt.Println(d.Size(), d.Bytes())
+
+	// Output:
+	// evicted "hello, "
+	// 2 10
+}
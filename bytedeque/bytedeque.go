@@ -0,0 +1,317 @@
+// bytedeque.go, jpad 2026
+
+/*
+Package bytedeque implements a double ended queue of bytes that can be used as
+an unbounded buffer. It implements io.Reader, io.Writer, io.ByteScanner,
+io.WriterTo and io.ReaderFrom. Unlike bytes.Buffer, any number of consumed
+bytes can be pushed back to the front of the buffer at the cost of the pushed
+back bytes only.
+*/
+package bytedeque
+
+import (
+	"container/list"
+	"errors"
+	"io"
+)
+
+const (
+	chunkSize   = 4096
+	chunkCenter = chunkSize / 2
+)
+
+// ErrUnreadByte is returned by UnreadByte when there is no byte to unread.
+var ErrUnreadByte = errors.New("bytedeque: UnreadByte: previous operation was not a read")
+
+//// Deque /////////////////////////////////////////////////////////////////////
+
+// Deque is a double ended queue of bytes. The zero value is not usable, use
+// New to create a deque.
+type Deque struct {
+	chunks   list.List
+	fC       _Chunk // front chunk (shortcut)
+	bC       _Chunk // back chunk (shortcut)
+	fI       int    // front item index
+	bI       int    // back item index
+	size     int
+	lastRead int // last byte read, or -1 if UnreadByte is not allowed
+}
+
+// New returns a pointer to an empty deque.
+func New() *Deque {
+	deque := Deque{}
+	deque.init()
+	return &deque
+}
+
+// PushFront adds a byte to the front of the deque.
+func (d *Deque) PushFront(item byte) {
+	d.lastRead = -1
+	if d.fI == 0 { // 'front' chunk full?
+		d.growFront()
+	}
+	d.fI--
+	d.fC[d.fI] = item
+	d.size++
+}
+
+// PushBack adds a byte to the back of the deque.
+func (d *Deque) PushBack(item byte) {
+	if d.bI == chunkSize-1 { // 'back' chunk full?
+		d.growBack()
+	}
+	d.bI++
+	d.bC[d.bI] = item
+	d.size++
+}
+
+// PopFront removes and returns the byte from the front of the deque.
+// Returns the zero value when the deque is empty.
+func (d *Deque) PopFront() byte {
+	d.lastRead = -1
+	if d.size <= 0 {
+		return 0
+	}
+	item := d.fC[d.fI]
+	d.discardFront(1)
+	return item
+}
+
+// PopBack removes and returns the byte from the back of the deque.
+// Returns the zero value when the deque is empty.
+func (d *Deque) PopBack() byte {
+	if d.size <= 0 {
+		return 0
+	}
+	item := d.bC[d.bI]
+	d.bI--
+	d.size--
+
+	if d.bI == -1 { // 'back' chunk empty?
+		if d.size == 0 { // deque is empty, reset it
+			d.reset()
+		} else {
+			d.chunks.Remove(d.chunks.Back())
+			d.bI = chunkSize - 1
+			d.bC = d.chunks.Back().Value.(_Chunk)
+		}
+	}
+
+	return item
+}
+
+// FrontItem returns the byte at the front of the deque.
+// Returns the zero value when the deque is empty.
+func (d *Deque) FrontItem() byte {
+	if d.size <= 0 {
+		return 0
+	}
+	return d.fC[d.fI]
+}
+
+// BackItem returns the byte at the back of the deque.
+// Returns the zero value when the deque is empty.
+func (d *Deque) BackItem() byte {
+	if d.size <= 0 {
+		return 0
+	}
+	return d.bC[d.bI]
+}
+
+// Size returns the number of bytes in the deque.
+func (d *Deque) Size() int {
+	return d.size
+}
+
+// Clear removes all bytes from the deque.
+func (d *Deque) Clear() {
+	d.init()
+}
+
+// Write appends the contents of p to the back of the deque. The returned error
+// is always nil.
+func (d *Deque) Write(p []byte) (n int, err error) {
+	n = len(p)
+	for len(p) > 0 {
+		if d.bI == chunkSize-1 {
+			d.growBack()
+		}
+		m := copy(d.bC[d.bI+1:], p)
+		d.bI += m
+		d.size += m
+		p = p[m:]
+	}
+	return n, nil
+}
+
+// WriteByte appends the byte c to the back of the deque. The returned error is
+// always nil.
+func (d *Deque) WriteByte(c byte) error {
+	d.PushBack(c)
+	return nil
+}
+
+// Read removes the next len(p) bytes from the front of the deque, or until the
+// deque is empty, and copies them into p. It returns io.EOF when the deque is
+// empty and len(p) > 0.
+func (d *Deque) Read(p []byte) (n int, err error) {
+	d.lastRead = -1
+	if d.size <= 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	for n < len(p) && d.size > 0 {
+		m := copy(p[n:], d.front())
+		d.discardFront(m)
+		n += m
+	}
+	if n > 0 {
+		d.lastRead = int(p[n-1])
+	}
+	return n, nil
+}
+
+// ReadByte removes and returns the byte from the front of the deque. It
+// returns io.EOF when the deque is empty.
+func (d *Deque) ReadByte() (byte, error) {
+	if d.size <= 0 {
+		d.lastRead = -1
+		return 0, io.EOF
+	}
+	c := d.PopFront()
+	d.lastRead = int(c)
+	return c, nil
+}
+
+// UnreadByte pushes the last byte returned by Read or ReadByte back to the
+// front of the deque. It returns ErrUnreadByte when the last operation on the
+// front of the deque was not a successful read.
+func (d *Deque) UnreadByte() error {
+	if d.lastRead < 0 {
+		return ErrUnreadByte
+	}
+	d.PushFront(byte(d.lastRead))
+	return nil
+}
+
+// UnreadBytes pushes p back to the front of the deque, so that the next read
+// returns the contents of p first.
+func (d *Deque) UnreadBytes(p []byte) {
+	d.lastRead = -1
+	for len(p) > 0 {
+		if d.fI == 0 {
+			d.growFront()
+		}
+		m := min(d.fI, len(p))
+		copy(d.fC[d.fI-m:d.fI], p[len(p)-m:])
+		d.fI -= m
+		d.size += m
+		p = p[:len(p)-m]
+	}
+}
+
+// WriteTo writes the contents of the deque to w, removing the written bytes
+// from the front of the deque, until the deque is empty or an error occurs.
+func (d *Deque) WriteTo(w io.Writer) (n int64, err error) {
+	d.lastRead = -1
+	for d.size > 0 {
+		span := d.front()
+		m, err := w.Write(span)
+		d.discardFront(m)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+		if m < len(span) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// ReadFrom appends data from r to the back of the deque until r returns
+// io.EOF or another error. The io.EOF itself is not returned.
+func (d *Deque) ReadFrom(r io.Reader) (n int64, err error) {
+	for {
+		if d.bI == chunkSize-1 {
+			d.growBack()
+		}
+		m, err := r.Read(d.bC[d.bI+1:])
+		if m < 0 {
+			panic("bytedeque: reader returned negative count from Read")
+		}
+		if m == 0 && d.bI == -1 { // don't keep an empty 'back' chunk
+			d.chunks.Remove(d.chunks.Back())
+			d.bI = chunkSize - 1
+			d.bC = d.chunks.Back().Value.(_Chunk)
+		}
+		d.bI += m
+		d.size += m
+		n += int64(m)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+func (d *Deque) init() {
+	d.reset()
+	chunk := make(_Chunk, chunkSize)
+	d.fC = chunk
+	d.bC = chunk
+	d.chunks.Init()
+	d.chunks.PushBack(chunk)
+	d.size = 0
+	d.lastRead = -1
+}
+
+func (d *Deque) reset() {
+	d.fI = chunkCenter + 1
+	d.bI = chunkCenter
+}
+
+// growFront adds a new chunk at the front.
+func (d *Deque) growFront() {
+	d.fC = make(_Chunk, chunkSize)
+	d.chunks.PushFront(d.fC)
+	d.fI = chunkSize
+}
+
+// growBack adds a new chunk at the back.
+func (d *Deque) growBack() {
+	d.bC = make(_Chunk, chunkSize)
+	d.chunks.PushBack(d.bC)
+	d.bI = -1
+}
+
+// front returns the bytes of the front chunk.
+func (d *Deque) front() []byte {
+	if d.chunks.Len() == 1 {
+		return d.fC[d.fI : d.bI+1]
+	}
+	return d.fC[d.fI:]
+}
+
+// discardFront removes n bytes, at most the length of front(), from the front
+// of the deque.
+func (d *Deque) discardFront(n int) {
+	d.fI += n
+	d.size -= n
+
+	if d.size == 0 { // deque is empty, reset it
+		d.reset()
+	} else if d.fI == chunkSize { // 'front' chunk empty?
+		d.chunks.Remove(d.chunks.Front())
+		d.fI = 0
+		d.fC = d.chunks.Front().Value.(_Chunk)
+	}
+}
+
+//// _Chunk ////////////////////////////////////////////////////////////////////
+
+type _Chunk []byte
@@ -0,0 +1,232 @@
+// bytedeque_test.go, jpad 2026
+
+package bytedeque_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/notnot/container/bytedeque"
+)
+
+var (
+	_ io.Reader      = (*bytedeque.Deque)(nil)
+	_ io.Writer      = (*bytedeque.Deque)(nil)
+	_ io.ByteScanner = (*bytedeque.Deque)(nil)
+	_ io.ByteWriter  = (*bytedeque.Deque)(nil)
+	_ io.WriterTo    = (*bytedeque.Deque)(nil)
+	_ io.ReaderFrom  = (*bytedeque.Deque)(nil)
+)
+
+func randomBytes(n int) []byte {
+	p := make([]byte, n)
+	rand.Read(p)
+	return p
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestEmpty(t *testing.T) {
+	deque := bytedeque.New()
+
+	if n, err := deque.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+		t.Errorf("got: %d, %v, want: 0, EOF", n, err)
+	}
+	if n, err := deque.Read(nil); n != 0 || err != nil {
+		t.Errorf("got: %d, %v, want: 0, <nil>", n, err)
+	}
+	if _, err := deque.ReadByte(); err != io.EOF {
+		t.Errorf("got: %v, want: EOF", err)
+	}
+	if err := deque.UnreadByte(); err != bytedeque.ErrUnreadByte {
+		t.Errorf("got: %v, want: %v", err, bytedeque.ErrUnreadByte)
+	}
+	if deque.PopFront() != 0 || deque.PopBack() != 0 {
+		t.Errorf("got: item, want: 0")
+	}
+	if deque.Size() != 0 {
+		t.Errorf("got: %d, want: 0", deque.Size())
+	}
+}
+
+func TestPushPop(t *testing.T) {
+	const N = 10000
+	deque := bytedeque.New()
+
+	for i := 0; i < N; i++ {
+		deque.PushFront(byte(i))
+		deque.PushBack(byte(i))
+	}
+	if deque.Size() != 2*N {
+		t.Errorf("got: %d, want: %d", deque.Size(), 2*N)
+	}
+	for i := N - 1; i >= 0; i-- {
+		if deque.FrontItem() != byte(i) || deque.BackItem() != byte(i) {
+			t.Fatalf("got: %d %d, want: %d", deque.FrontItem(), deque.BackItem(), byte(i))
+		}
+		if front := deque.PopFront(); front != byte(i) {
+			t.Fatalf("got: %d, want: %d", front, byte(i))
+		}
+		if back := deque.PopBack(); back != byte(i) {
+			t.Fatalf("got: %d, want: %d", back, byte(i))
+		}
+	}
+	if deque.Size() != 0 {
+		t.Errorf("got: %d, want: 0", deque.Size())
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	deque := bytedeque.New()
+	data := randomBytes(100000)
+
+	deque.Write(data)
+	if deque.Size() != len(data) {
+		t.Errorf("got: %d, want: %d", deque.Size(), len(data))
+	}
+	if err := iotest.TestReader(deque, data); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnread(t *testing.T) {
+	deque := bytedeque.New()
+	deque.Write([]byte("world"))
+
+	c, _ := deque.ReadByte()
+	if err := deque.UnreadByte(); err != nil {
+		t.Errorf("got: %v, want: <nil>", err)
+	}
+	if err := deque.UnreadByte(); err != bytedeque.ErrUnreadByte {
+		t.Errorf("got: %v, want: %v", err, bytedeque.ErrUnreadByte)
+	}
+	if deque.FrontItem() != c {
+		t.Errorf("got: %c, want: %c", deque.FrontItem(), c)
+	}
+
+	deque.UnreadBytes([]byte("hello, "))
+	deque.UnreadBytes(bytes.Repeat([]byte("."), 10000))
+	got, _ := io.ReadAll(deque)
+	want := strings.Repeat(".", 10000) + "hello, world"
+	if string(got) != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	deque := bytedeque.New()
+	data := randomBytes(100000)
+
+	n, err := deque.ReadFrom(iotest.OneByteReader(bytes.NewReader(data[:5000])))
+	if n != 5000 || err != nil {
+		t.Errorf("got: %d, %v, want: 5000, <nil>", n, err)
+	}
+	n, err = deque.ReadFrom(bytes.NewReader(data[5000:]))
+	if n != int64(len(data)-5000) || err != nil {
+		t.Errorf("got: %d, %v, want: %d, <nil>", n, err, len(data)-5000)
+	}
+
+	var buf bytes.Buffer
+	n, err = deque.WriteTo(&buf)
+	if n != int64(len(data)) || err != nil {
+		t.Errorf("got: %d, %v, want: %d, <nil>", n, err, len(data))
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("got: different bytes, want: same bytes")
+	}
+	if deque.Size() != 0 {
+		t.Errorf("got: %d, want: 0", deque.Size())
+	}
+}
+
+func TestReadFrom_error(t *testing.T) {
+	deque := bytedeque.New()
+	r := iotest.TimeoutReader(bytes.NewReader(randomBytes(10)))
+
+	n, err := deque.ReadFrom(r)
+	if n != 10 || err != iotest.ErrTimeout {
+		t.Errorf("got: %d, %v, want: 10, %v", n, err, iotest.ErrTimeout)
+	}
+	if deque.Size() != 10 {
+		t.Errorf("got: %d, want: 10", deque.Size())
+	}
+}
+
+// TestRandom checks random reads, writes and unreads against a byte slice.
+func TestRandom(t *testing.T) {
+	const N = 10000
+	deque := bytedeque.New()
+	model := []byte{}
+
+	for i := 0; i < N; i++ {
+		switch rand.Intn(4) {
+		case 0:
+			p := randomBytes(rand.Intn(10000))
+			deque.Write(p)
+			model = append(model, p...)
+		case 1:
+			p := randomBytes(rand.Intn(100))
+			deque.UnreadBytes(p)
+			model = append(append([]byte{}, p...), model...)
+		case 2:
+			p := make([]byte, rand.Intn(10000))
+			n, _ := deque.Read(p)
+			if !bytes.Equal(p[:n], model[:n]) {
+				t.Fatalf("%d: got: different bytes, want: same bytes", i)
+			}
+			model = model[n:]
+		case 3:
+			if deque.Size() > 0 {
+				if c := deque.PopBack(); c != model[len(model)-1] {
+					t.Fatalf("%d: got: %d, want: %d", i, c, model[len(model)-1])
+				}
+				model = model[:len(model)-1]
+			}
+		}
+		if deque.Size() != len(model) {
+			t.Fatalf("%d: got: %d, want: %d", i, deque.Size(), len(model))
+		}
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkWriteRead_64K(b *testing.B) {
+	deque := bytedeque.New()
+	data := randomBytes(64 * 1024)
+	p := make([]byte, 4096)
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		deque.Write(data)
+		for deque.Size() > 0 {
+			deque.Read(p)
+		}
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleDeque_UnreadBytes() {
+	deque := bytedeque.New()
+	deque.Write([]byte("GET /index.html"))
+
+	// peek at the method and push it back
+	method := make([]byte, 3)
+	deque.Read(method)
+	deque.UnreadBytes(method)
+
+	fmt.Printf("%s\n", method)
+	deque.WriteTo(os.Stdout)
+	fmt.Println()
+
+	// Output:
+	// GET
+	// GET /index.html
+}
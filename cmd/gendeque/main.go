@@ -0,0 +1,138 @@
+// main.go, jpad 2026
+
+/*
+Gendeque generates a double ended queue package specialized for one item type,
+like deque_int is for int. The generated package has the API of deque_int and
+comes with its own tests.
+
+Usage:
+
+	gendeque -type=float64 [-package=deque_f64] [-output=dir] [-import=path] [-item=expr]
+
+The flags are:
+
+	-type
+		the item type, e.g. float64 or jobs.Job (required)
+	-package
+		the name of the generated package; defaults to deque_<type>
+	-output
+		the directory to write the package to; defaults to the package name
+	-import
+		the import path of the package that declares the item type
+	-item
+		a Go expression of the item type built from the int i, used by the
+		generated tests to create distinct items, e.g. "jobs.Job{ID: i}";
+		it can be omitted for the predeclared numeric, bool and string types
+
+The item type must be comparable for the generated tests to compile.
+
+It is typically run from a go:generate directive:
+
+	//go:generate gendeque -type=float64 -package=deque_f64 -output=.
+*/
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// config holds the template data of a generated package.
+type config struct {
+	Command     string   // command line, for the generated file headers
+	Package     string   // package name
+	Type        string   // item type
+	Import      string   // import path declaring Type, if any
+	Item        string   // test item expression of int i
+	TestImports []string // imports needed by Type and Item in the tests
+}
+
+func main() {
+	var cfg config
+	var output string
+	flag.StringVar(&cfg.Type, "type", "", "item type (required)")
+	flag.StringVar(&cfg.Package, "package", "", "package name (default deque_<type>)")
+	flag.StringVar(&output, "output", "", "output directory (default package name)")
+	flag.StringVar(&cfg.Import, "import", "", "import path of the package declaring the item type")
+	flag.StringVar(&cfg.Item, "item", "", "test item expression of int i")
+	flag.Parse()
+	cfg.Command = strings.Join(append([]string{"gendeque"}, os.Args[1:]...), " ")
+
+	if err := generate(&cfg, output); err != nil {
+		fmt.Fprintln(os.Stderr, "gendeque:", err)
+		os.Exit(1)
+	}
+}
+
+// generate completes the configuration and writes the package to output.
+func generate(cfg *config, output string) error {
+	if cfg.Type == "" {
+		return errors.New("-type is required")
+	}
+	if cfg.Package == "" {
+		cfg.Package = "deque_" + sanitize(cfg.Type)
+	}
+	if output == "" {
+		output = cfg.Package
+	}
+	if cfg.Item == "" {
+		switch cfg.Type {
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"byte", "rune", "float32", "float64", "complex64", "complex128":
+			cfg.Item = cfg.Type + "(i)"
+		case "bool":
+			cfg.Item = "i%2 == 1"
+		case "string":
+			cfg.Item = "strconv.Itoa(i)"
+			cfg.TestImports = append(cfg.TestImports, "strconv")
+		default:
+			return fmt.Errorf("-item is required for type %s", cfg.Type)
+		}
+	}
+	if cfg.Import != "" { // the tests always refer to Type
+		cfg.TestImports = append(cfg.TestImports, cfg.Import)
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return err
+	}
+	for _, name := range []string{"deque.go", "deque_test.go", "check.go", "nocheck.go"} {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, name+".tmpl", cfg); err != nil {
+			return err
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(output, name), src, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sanitize turns a type expression into a lower case identifier suffix.
+func sanitize(typ string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, typ)
+}
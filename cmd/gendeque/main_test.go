@@ -0,0 +1,72 @@
+// main_test.go, jpad 2026
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestSanitize(t *testing.T) {
+	for typ, want := range map[string]string{
+		"float64":  "float64",
+		"jobs.Job": "jobs_job",
+		"*Node":    "_node",
+	} {
+		if got := sanitize(typ); got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	}
+}
+
+func TestGenerate_itemRequired(t *testing.T) {
+	cfg := config{Type: "Point"}
+	if err := generate(&cfg, t.TempDir()); err == nil {
+		t.Errorf("got: <nil>, want: error")
+	}
+}
+
+// TestGenerate generates packages for a few types and runs their tests.
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of generated packages in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/gen\n\ngo 1.23\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		cfg    config
+		output string
+		pkg    string // generated package name
+	}{
+		{config{Type: "float64", Package: "deque_f64"}, "f64", "deque_f64"},
+		{config{Type: "string"}, "string", "deque_string"},
+		{config{Type: "time.Duration", Import: "time", Item: "time.Duration(i)"}, "duration", "deque_time_duration"},
+		{config{Type: "rand.PCG", Import: "math/rand/v2", Item: "*rand.NewPCG(uint64(i), 0)"}, "pcg", "deque_rand_pcg"},
+	} {
+		tc.cfg.Command = "gendeque"
+		if err := generate(&tc.cfg, filepath.Join(dir, tc.output)); err != nil {
+			t.Fatalf("%s: %v", tc.cfg.Type, err)
+		}
+		if tc.cfg.Package != tc.pkg {
+			t.Errorf("got: %s, want: %s", tc.cfg.Package, tc.pkg)
+		}
+	}
+
+	cmd := exec.Command(goTool, "test", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go test: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,131 @@
+// chan.go, jpad 2026
+
+package deque
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+)
+
+//// channel adapters //////////////////////////////////////////////////////////
+
+// FromChan returns a deque holding the items received from ch, in order of
+// arrival. It receives until ch is closed or ctx is done; in the latter case
+// the deque holds the items received so far and ctx.Err() tells why.
+func FromChan[T any](ctx context.Context, ch <-chan T) *Deque {
+	d := New()
+	PushBackFromChan(ctx, d, ch, -1)
+	return d
+}
+
+// PushBackFromChan receives at most max items from ch and adds them to the
+// back of d; a negative max receives without limit. It returns the number of
+// items added, and io.EOF when ch was closed or ctx.Err() when ctx was done
+// before max items were received.
+func PushBackFromChan[T any](ctx context.Context, d *Deque, ch <-chan T, max int) (int, error) {
+	n := 0
+	for max < 0 || n < max {
+		select {
+		case item, ok := <-ch:
+			if !ok {
+				return n, io.EOF
+			}
+			d.PushBack(item)
+			n++
+		case <-ctx.Done():
+			return n, ctx.Err()
+		}
+	}
+	return n, nil
+}
+
+// DrainTo sends the items of the deque, from the front, on ch until the deque
+// is empty or ctx is done. Each send blocks until ch accepts it, and an item
+// is only removed once it has been sent. It returns the number of items sent,
+// and ctx.Err() when ctx was done before the deque was drained. ch is not
+// closed.
+func (d *Deque) DrainTo(ctx context.Context, ch chan<- interface{}) (int, error) {
+	n := 0
+	for d.size > 0 {
+		select {
+		case ch <- d.FrontItem():
+			d.PopFront()
+			n++
+		case <-ctx.Done():
+			return n, ctx.Err()
+		}
+	}
+	return n, nil
+}
+
+//// UnboundedChan /////////////////////////////////////////////////////////////
+
+// UnboundedChan is a channel pair with an unbounded buffer in between. Sends
+// on In never block; the items are buffered in a queue by a pump goroutine and
+// received from Out in first in, first out order.
+//
+// Closing In shuts the channel down cleanly: the buffered items are still
+// delivered on Out, after which Out is closed and the pump goroutine exits.
+// A consumer that stops receiving before Out is closed leaks the goroutine
+// and the buffered items.
+type UnboundedChan[T any] struct {
+	In  chan<- T
+	Out <-chan T
+
+	n atomic.Int64 // buffered items
+}
+
+// NewUnbounded returns a pointer to a new unbounded channel, with its pump
+// goroutine started.
+func NewUnbounded[T any]() *UnboundedChan[T] {
+	in, out := make(chan T), make(chan T)
+	c := &UnboundedChan[T]{In: in, Out: out}
+	go c.pump(in, out)
+	return c
+}
+
+// NewUnboundedChan returns the send and receive ends of a new unbounded
+// channel. Use NewUnbounded when the buffer length has to be probed.
+func NewUnboundedChan[T any]() (in chan<- T, out <-chan T) {
+	c := NewUnbounded[T]()
+	return c.In, c.Out
+}
+
+// Len returns the number of items buffered between In and Out. An item that
+// is being handed to a receiver may or may not be counted.
+func (c *UnboundedChan[T]) Len() int {
+	return int(c.n.Load())
+}
+
+// pump moves the items from in to out through a queue, until in is closed and
+// the queue is drained.
+func (c *UnboundedChan[T]) pump(in <-chan T, out chan<- T) {
+	defer close(out)
+
+	q := NewQueue[T]()
+	for in != nil || q.Size() > 0 {
+		if q.Size() == 0 { // nothing to send, only receive
+			item, ok := <-in
+			if !ok {
+				return
+			}
+			q.Enqueue(item)
+			c.n.Store(int64(q.Size()))
+			continue
+		}
+
+		front, _ := q.Peek()
+		select {
+		case item, ok := <-in:
+			if !ok {
+				in = nil // drain the queue
+				continue
+			}
+			q.Enqueue(item)
+		case out <- front:
+			q.Dequeue()
+		}
+		c.n.Store(int64(q.Size()))
+	}
+}
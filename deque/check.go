@@ -0,0 +1,8 @@
+// check.go, jpad 2026
+
+//go:build !deque_nocheck
+
+package deque
+
+// checkMods enables the detection of deque modifications during iteration.
+const checkMods = true
@@ -0,0 +1,64 @@
+// check_test.go, jpad 2026
+
+//go:build !deque_nocheck
+
+package deque_test
+
+import (
+	"testing"
+
+	"github.com/notnot/container/deque"
+)
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func expectModPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != deque.ErrConcurrentModification {
+			t.Errorf("%s: got: %v, want: %v", name, r, deque.ErrConcurrentModification)
+		}
+	}()
+	f()
+}
+
+func TestIterate_modified(t *testing.T) {
+	const N = 100
+	deque := deque.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+
+	it := deque.Front()
+	deque.PushFront(-1)
+	expectModPanic(t, "PushFront", func() { it.Next() })
+
+	it = deque.Back()
+	deque.PopBack()
+	expectModPanic(t, "PopBack", func() { it.Prev() })
+
+	it = deque.Front()
+	deque.Clear()
+	expectModPanic(t, "Clear", func() { it.Next() })
+
+	deque.PushBack(1)
+	deque.PushBack(2)
+	expectModPanic(t, "Chunks", func() {
+		for range deque.Chunks() {
+			deque.PopFront()
+		}
+	})
+}
+
+func TestIterate_unmodified(t *testing.T) {
+	deque := deque.New()
+	deque.PushBack(1)
+	deque.PushBack(2)
+
+	it := deque.Front()
+	_ = deque.FrontItem()
+	_ = deque.Size()
+	if it.Next() == nil {
+		t.Errorf("got: <nil>, want: iterator")
+	}
+}
@@ -0,0 +1,137 @@
+// compare.go, jpad 2026
+
+package deque
+
+import (
+	"iter"
+	"sync/atomic"
+)
+
+// Equal reports whether a and b hold the same items in the same order. Items
+// are compared with ==, which panics for items of non-comparable types.
+func Equal(a, b *Deque) bool {
+	return EqualFunc(a, b, func(x, y interface{}) bool { return x == y })
+}
+
+// EqualFunc reports whether a and b hold the same items in the same order,
+// using eq to compare the items.
+func EqualFunc(a, b *Deque, eq func(x, y interface{}) bool) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+	equal := true
+	zip(a, b, func(x, y []interface{}) bool {
+		for i := range x {
+			if !eq(x[i], y[i]) {
+				equal = false
+				return false
+			}
+		}
+		return true
+	})
+	return equal
+}
+
+// Compare compares the items of a and b lexicographically from front to back,
+// using cmp to compare the items. cmp returns a negative number, zero or a
+// positive number when x is less than, equal to or greater than y. The result
+// is -1 if a < b, 0 if a == b and +1 if a > b; a deque that is a prefix of the
+// other is the lesser one.
+func Compare(a, b *Deque, cmp func(x, y interface{}) int) int {
+	c := 0
+	zip(a, b, func(x, y []interface{}) bool {
+		for i := range x {
+			if c = cmp(x[i], y[i]); c != 0 {
+				return false
+			}
+		}
+		return true
+	})
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return +1
+	case a.Size() < b.Size():
+		return -1
+	case a.Size() > b.Size():
+		return +1
+	}
+	return 0
+}
+
+// Clone returns a copy of the deque in time proportional to the number of
+// chunks. The chunks are shared by both deques until either one writes to a
+// chunk, which then gets copied (copy on write). A clone can be read while the
+// original is modified, which makes it suitable as a consistent snapshot.
+// Handles keep referring to the items of the original deque.
+func (d *Deque) Clone() *Deque {
+	c := &Deque{fC: d.fC, bC: d.bC, fI: d.fI, bI: d.bI, size: d.size, dead: d.dead}
+	c.chunks.Init()
+	for e := d.chunks.Front(); e != nil; e = e.Next() {
+		b := e.Value.(*_Block)
+		if b.refs == nil {
+			b.refs = new(atomic.Int32)
+			b.refs.Store(1)
+			d.shared++
+		}
+		b.refs.Add(1)
+		c.chunks.PushBack(&_Block{chunk: b.chunk, refs: b.refs, dead: b.dead})
+	}
+	c.shared = c.chunks.Len()
+	return c
+}
+
+// zip calls f with equally long runs of items taken from the fronts of a and
+// b, until either deque is exhausted or f returns false.
+func zip(a, b *Deque, f func(x, y []interface{}) bool) {
+	if a.size <= 0 || b.size <= 0 {
+		return
+	}
+	if a.dead > 0 || b.dead > 0 { // let Chunks step over the tombstones
+		nextA, stopA := iter.Pull(a.Chunks())
+		defer stopA()
+		nextB, stopB := iter.Pull(b.Chunks())
+		defer stopB()
+		var x, y []interface{}
+		for {
+			var ok bool
+			if len(x) == 0 {
+				if x, ok = nextA(); !ok {
+					return
+				}
+			}
+			if len(y) == 0 {
+				if y, ok = nextB(); !ok {
+					return
+				}
+			}
+			n := min(len(x), len(y))
+			if !f(x[:n], y[:n]) {
+				return
+			}
+			x, y = x[n:], y[n:]
+		}
+	}
+	ea, eb := a.chunks.Front(), b.chunks.Front()
+	x, y := a.span(ea), b.span(eb)
+	for {
+		n := min(len(x), len(y))
+		if !f(x[:n], y[:n]) {
+			return
+		}
+		x, y = x[n:], y[n:]
+		if len(x) == 0 {
+			if ea = ea.Next(); ea == nil {
+				return
+			}
+			x = a.span(ea)
+		}
+		if len(y) == 0 {
+			if eb = eb.Next(); eb == nil {
+				return
+			}
+			y = b.span(eb)
+		}
+	}
+}
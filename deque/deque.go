@@ -9,6 +9,9 @@ package deque
 
 import (
 	"container/list"
+	"errors"
+	"iter"
+	"sync/atomic"
 )
 
 const (
@@ -16,26 +19,31 @@ const (
 	chunkCenter = chunkSize / 2
 )
 
+// ErrConcurrentModification is the panic value raised when an iterator is
+// used after the deque it iterates over has been modified.
+var ErrConcurrentModification = errors.New("deque: concurrent modification during iteration")
+
 //// Deque /////////////////////////////////////////////////////////////////////
 
 // Deque is a double ended queue that can handle items of any type.
 type Deque struct {
-	chunks list.List
-	fC     _Chunk // front chunk (shortcut)
-	bC     _Chunk // back chunk (shortcut)
-	fI     int    // front item index
-	bI     int    // back item index
-	size   int
+	chunks  list.List
+	fC      _Chunk // front chunk (shortcut)
+	bC      _Chunk // back chunk (shortcut)
+	fI      int    // front item index
+	bI      int    // back item index
+	size    int
+	mods    int // modification counter, see Iterator
+	shared  int // number of chunks shared with other deques, see Clone
+	dead    int // number of tombstones, see RemoveHandle
+	handles int // number of valid handles, see RemoveHandle
+	obs     Observer
 }
 
 // New returns a pointer to an empty deque.
 func New() *Deque {
 	deque := Deque{}
-	deque.reset()
-	chunk := make(_Chunk, chunkSize)
-	deque.fC = chunk
-	deque.bC = chunk
-	deque.chunks.PushBack(chunk)
+	deque.init()
 	return &deque
 }
 
@@ -44,12 +52,21 @@ func (d *Deque) PushFront(item interface{}) {
 	if d.fI == 0 { // 'front' chunk full?
 		// add a new chunk at the front
 		d.fC = make(_Chunk, chunkSize)
-		d.chunks.PushFront(d.fC)
+		d.chunks.PushFront(&_Block{chunk: d.fC})
 		d.fI = chunkSize
+		if d.obs != nil {
+			d.obs.Grew(1, d.chunks.Len())
+		}
+	} else if d.shared > 0 {
+		d.own(d.chunks.Front())
 	}
 	d.fI--
 	d.fC[d.fI] = item
 	d.size++
+	d.mods++
+	if d.obs != nil {
+		d.obs.Pushed(1, d.size)
+	}
 }
 
 // PushBack adds an item to the back of the deque.
@@ -57,34 +74,59 @@ func (d *Deque) PushBack(item interface{}) {
 	if d.bI == chunkSize-1 { // 'back' chunk full?
 		// add a new chunk at the back
 		d.bC = make(_Chunk, chunkSize)
-		d.chunks.PushBack(d.bC)
+		d.chunks.PushBack(&_Block{chunk: d.bC})
 		d.bI = -1
+		if d.obs != nil {
+			d.obs.Grew(1, d.chunks.Len())
+		}
+	} else if d.shared > 0 {
+		d.own(d.chunks.Back())
 	}
 	d.bI++
 	d.bC[d.bI] = item
 	d.size++
+	d.mods++
+	if d.obs != nil {
+		d.obs.Pushed(1, d.size)
+	}
 }
 
 // PopFront removes and returns the item from the front of the deque.
 // Returns nil when the deque is empty.
 func (d *Deque) PopFront() interface{} {
-	if d.size < 0 {
+	if d.size <= 0 {
 		return nil
 	}
 	item := d.fC[d.fI]
-	d.fC[d.fI] = nil // clear ? necessary
+	if d.handles > 0 {
+		d.unhandle(d.chunks.Front(), d.fI)
+	}
+	if d.shared == 0 || !isShared(d.chunks.Front()) {
+		d.fC[d.fI] = nil // clear ? necessary
+	}
 	d.fI++
 	d.size--
+	d.mods++
 
 	if d.fI == chunkSize { // 'front' chunk empty?
 		if d.size == 0 { // deque is empty, reset it
 			d.reset()
 		} else {
+			d.release(d.chunks.Front())
 			d.chunks.Remove(d.chunks.Front())
 			d.fI = 0
-			d.fC = d.chunks.Front().Value.(_Chunk)
+			d.fC = d.chunks.Front().Value.(*_Block).chunk
+			if d.obs != nil {
+				d.obs.Shrank(1, d.chunks.Len())
+			}
 		}
 	}
+	if d.dead > 0 {
+		d.trimFront()
+	}
+	if d.obs != nil {
+		d.obs.Popped(1, d.size)
+	}
 
 	return item
 }
@@ -92,34 +134,58 @@ func (d *Deque) PopFront() interface{} {
 // PopBack removes and returns the item from the back of the deque.
 // Returns nil when the deque is empty.
 func (d *Deque) PopBack() interface{} {
-	if d.size < 0 {
+	if d.size <= 0 {
 		return nil
 	}
 	item := d.bC[d.bI]
-	d.bC[d.bI] = nil // clear ? necessary
+	if d.handles > 0 {
+		d.unhandle(d.chunks.Back(), d.bI)
+	}
+	if d.shared == 0 || !isShared(d.chunks.Back()) {
+		d.bC[d.bI] = nil // clear ? necessary
+	}
 	d.bI--
 	d.size--
+	d.mods++
 
 	if d.bI == -1 { // 'back' chunk empty?
 		if d.size == 0 { // deque is empty, reset it
 			d.reset()
 		} else {
+			d.release(d.chunks.Back())
 			d.chunks.Remove(d.chunks.Back())
 			d.bI = chunkSize - 1
-			d.bC = d.chunks.Back().Value.(_Chunk)
+			d.bC = d.chunks.Back().Value.(*_Block).chunk
+			if d.obs != nil {
+				d.obs.Shrank(1, d.chunks.Len())
+			}
 		}
 	}
+	if d.dead > 0 {
+		d.trimBack()
+	}
+	if d.obs != nil {
+		d.obs.Popped(1, d.size)
+	}
 
 	return item
 }
 
 // FrontItem returns the item at the front of the deque.
+// Returns nil when the deque is empty.
 func (d *Deque) FrontItem() interface{} {
+	if d.size == 0 { // popped slots of shared chunks aren't cleared
+		return nil
+	}
 	return d.fC[d.fI]
 }
 
 // BackItem returns the item at the back of the deque.
+// Returns nil when the deque is empty.
 func (d *Deque) BackItem() interface{} {
+	if d.size == 0 { // popped slots of shared chunks aren't cleared
+		return nil
+	}
 	return d.bC[d.bI]
 }
 
@@ -134,9 +200,10 @@ func (d *Deque) Front() *Iterator {
 		Value: d.fC[d.fI],
 		deque: d,
 		node:  fNode,
-		chunk: fNode.Value.(_Chunk),
+		chunk: fNode.Value.(*_Block).chunk,
 		i:     d.fI,
 		pos:   0,
+		mods:  d.mods,
 	}
 }
 
@@ -151,20 +218,316 @@ func (d *Deque) Back() *Iterator {
 		Value: d.bC[d.bI],
 		deque: d,
 		node:  bNode,
-		chunk: bNode.Value.(_Chunk),
+		chunk: bNode.Value.(*_Block).chunk,
 		i:     d.bI,
 		pos:   d.size - 1,
+		mods:  d.mods,
+	}
+}
+
+// Seek returns an iterator positioned at the item with index i, counting from
+// the front of the deque, or nil if there is no such item. It walks the chunks
+// from the nearest end of the deque.
+func (d *Deque) Seek(i int) *Iterator {
+	if i < 0 || i >= d.size {
+		return nil
+	}
+	var node *list.Element
+	var ci int
+	if i < d.size/2 {
+		node, ci = d.walk(d.chunks.Front(), d.fI, i)
+	} else {
+		node, ci = d.walk(d.chunks.Back(), d.bI, i-(d.size-1))
+	}
+	chunk := node.Value.(*_Block).chunk
+	return &Iterator{
+		Value: chunk[ci],
+		deque: d,
+		node:  node,
+		chunk: chunk,
+		i:     ci,
+		pos:   i,
+		mods:  d.mods,
+	}
+}
+
+// Chunks returns an iterator over the live items of the deque, one contiguous
+// chunk at a time, from front to back; a chunk with tombstones, see
+// RemoveHandle, is split into the runs of items between them. Each yielded
+// slice aliases the deque's storage, which may be shared with clones of the
+// deque, so it must not be written to. It is only valid until the deque is
+// modified; modifying the deque before the iteration is done panics with
+// ErrConcurrentModification.
+func (d *Deque) Chunks() iter.Seq[[]interface{}] {
+	return func(yield func([]interface{}) bool) {
+		if d.size <= 0 {
+			return
+		}
+		mods := d.mods
+		next := func(run []interface{}) bool {
+			if !yield(run) {
+				return false
+			}
+			if checkMods && d.mods != mods {
+				panic(ErrConcurrentModification)
+			}
+			return true
+		}
+		for e := d.chunks.Front(); e != nil; e = e.Next() {
+			if !d.runs(e, false, next) {
+				return
+			}
+		}
+	}
+}
+
+// ChunksBackward is like Chunks but yields the chunks from back to front. The
+// items within each slice remain in front to back order.
+func (d *Deque) ChunksBackward() iter.Seq[[]interface{}] {
+	return func(yield func([]interface{}) bool) {
+		if d.size <= 0 {
+			return
+		}
+		mods := d.mods
+		next := func(run []interface{}) bool {
+			if !yield(run) {
+				return false
+			}
+			if checkMods && d.mods != mods {
+				panic(ErrConcurrentModification)
+			}
+			return true
+		}
+		for e := d.chunks.Back(); e != nil; e = e.Prev() {
+			if !d.runs(e, true, next) {
+				return
+			}
+		}
 	}
 }
 
+// DiscardFront removes the n items at the front of the deque, or all items if
+// n exceeds its size, and returns the number of removed items. Chunks that get
+// emptied are released as a whole, without visiting their items.
+func (d *Deque) DiscardFront(n int) int {
+	if n <= 0 || d.size <= 0 {
+		return 0
+	}
+	if n >= d.size {
+		n = d.size
+		d.Clear()
+		return n
+	}
+
+	released := 0
+	for left := n; left > 0; {
+		e := d.chunks.Front()
+		b := e.Value.(*_Block)
+		if live := chunkSize - d.fI - b.dead; left >= live { // 'front' chunk emptied?
+			if d.handles > 0 {
+				d.unhandleAll(e)
+			}
+			d.dead -= b.dead
+			d.release(e)
+			d.chunks.Remove(e)
+			d.fI = 0
+			d.fC = d.chunks.Front().Value.(*_Block).chunk
+			left -= live
+			released++
+		} else if b.dead == 0 && d.handles == 0 {
+			if d.shared == 0 || !isShared(e) {
+				clear(d.fC[d.fI : d.fI+left])
+			}
+			d.fI += left
+			left = 0
+		} else { // step over the tombstones and invalidate the handles
+			owned := d.shared == 0 || !isShared(e)
+			for ; left > 0; d.fI++ {
+				if isDead(d.fC[d.fI]) {
+					b.dead--
+					d.dead--
+				} else {
+					if d.handles > 0 {
+						d.unhandle(e, d.fI)
+					}
+					left--
+				}
+				if owned {
+					d.fC[d.fI] = nil
+				}
+			}
+		}
+	}
+	d.size -= n
+	if d.dead > 0 {
+		d.trimFront()
+	}
+	d.mods++
+	if d.obs != nil {
+		if released > 0 {
+			d.obs.Shrank(released, d.chunks.Len())
+		}
+		d.obs.Popped(n, d.size)
+	}
+	return n
+}
+
+// IndexFunc returns the index of the first item satisfying pred, counting from
+// the front of the deque, or -1 if there is none.
+func (d *Deque) IndexFunc(pred func(item interface{}) bool) int {
+	i := 0
+	for chunk := range d.Chunks() {
+		for _, item := range chunk {
+			if pred(item) {
+				return i
+			}
+			i++
+		}
+	}
+	return -1
+}
+
+// LastIndexFunc returns the index of the last item satisfying pred, counting
+// from the front of the deque, or -1 if there is none.
+func (d *Deque) LastIndexFunc(pred func(item interface{}) bool) int {
+	i := d.size - 1
+	for chunk := range d.ChunksBackward() {
+		for j := len(chunk) - 1; j >= 0; j-- {
+			if pred(chunk[j]) {
+				return i
+			}
+			i--
+		}
+	}
+	return -1
+}
+
+// ContainsFunc reports whether at least one item satisfies pred.
+func (d *Deque) ContainsFunc(pred func(item interface{}) bool) bool {
+	return d.IndexFunc(pred) >= 0
+}
+
+// DeleteFunc removes all items satisfying pred and returns the number of
+// removed items. The remaining items keep their order and are compacted
+// towards the front in a single pass; chunks left empty are released. pred
+// must not modify the deque.
+func (d *Deque) DeleteFunc(pred func(item interface{}) bool) int {
+	if d.size <= 0 {
+		return 0
+	}
+
+	if d.shared > 0 { // every chunk gets written to
+		for e := d.chunks.Front(); e != nil; e = e.Next() {
+			d.own(e)
+		}
+	}
+
+	// the write position trails the read position; wNode holds the last
+	// kept item once there is one. Tombstones are dropped on the way.
+	wNode, wI := d.chunks.Front(), d.fI
+	wChunk := d.fC
+	kept, dead := 0, d.dead
+	for e := d.chunks.Front(); e != nil; e = e.Next() {
+		chunk := d.span(e)
+		lo := 0 // chunk index of chunk[0]
+		if e == d.chunks.Front() {
+			lo = d.fI
+		}
+		for i, item := range chunk {
+			chunk[i] = nil // let go of the item, unless it is kept below
+			if dead > 0 && isDead(item) {
+				continue
+			}
+			if pred(item) {
+				if d.handles > 0 {
+					d.unhandle(e, lo+i)
+				}
+				continue
+			}
+			if wI == chunkSize { // next chunk?
+				wNode = wNode.Next()
+				wChunk = wNode.Value.(*_Block).chunk
+				wI = 0
+			}
+			wChunk[wI] = item
+			if d.handles > 0 {
+				d.moveHandle(e, lo+i, wNode, wI)
+			}
+			wI++
+			kept++
+		}
+		e.Value.(*_Block).dead = 0
+	}
+	d.dead = 0
+
+	deleted := d.size - kept
+	if deleted == 0 && dead == 0 {
+		return 0
+	}
+	released := 0
+	for d.chunks.Back() != wNode { // release the emptied chunks
+		d.release(d.chunks.Back())
+		d.chunks.Remove(d.chunks.Back())
+		released++
+	}
+	d.bC = wChunk
+	d.bI = wI - 1
+	d.size = kept
+	if kept == 0 { // deque is empty, reset it
+		d.reset()
+	}
+	d.mods++
+	if d.obs != nil {
+		if released > 0 {
+			d.obs.Shrank(released, d.chunks.Len())
+		}
+		if deleted > 0 {
+			d.obs.Popped(deleted, d.size)
+		}
+	}
+	return deleted
+}
+
 // Size returns the number of items in the deque.
 func (d *Deque) Size() int {
 	return d.size
 }
 
+// ChunkCount returns the number of chunks held by the deque, see Observer.
+func (d *Deque) ChunkCount() int {
+	return d.chunks.Len()
+}
+
 // Clear removes all items from the deque.
 func (d *Deque) Clear() {
-	*d = *New()
+	if d.shared > 0 || d.handles > 0 {
+		for e := d.chunks.Front(); e != nil; e = e.Next() {
+			d.release(e)
+			d.unhandleAll(e)
+		}
+	}
+	size, chunks := d.size, d.chunks.Len()
+	d.init()
+	d.mods++
+	if d.obs != nil { // all chunks are replaced by a fresh one
+		d.obs.Shrank(chunks, 0)
+		d.obs.Grew(1, 1)
+		if size > 0 {
+			d.obs.Popped(size, 0)
+		}
+	}
+}
+
+func (d *Deque) init() {
+	d.reset()
+	chunk := make(_Chunk, chunkSize)
+	d.fC = chunk
+	d.bC = chunk
+	d.chunks.Init()
+	d.chunks.PushBack(&_Block{chunk: chunk})
+	d.size = 0
+	d.shared = 0
+	d.dead = 0
 }
 
 func (d *Deque) reset() {
@@ -172,9 +535,40 @@ func (d *Deque) reset() {
 	d.bI = chunkCenter
 }
 
+// span returns the live part of the chunk held by node e.
+func (d *Deque) span(e *list.Element) []interface{} {
+	chunk := e.Value.(*_Block).chunk
+	lo, hi := 0, chunkSize
+	if e == d.chunks.Front() {
+		lo = d.fI
+	}
+	if e == d.chunks.Back() {
+		hi = d.bI + 1
+	}
+	return chunk[lo:hi:hi]
+}
+
+// locate returns the chunk node and item index that lie n items away from
+// item index i in the chunk held by node, skipping whole chunks at once.
+func locate(node *list.Element, i, n int) (*list.Element, int) {
+	i += n
+	for i >= chunkSize {
+		node = node.Next()
+		i -= chunkSize
+	}
+	for i < 0 {
+		node = node.Prev()
+		i += chunkSize
+	}
+	return node, i
+}
+
 //// Iterator //////////////////////////////////////////////////////////////////
 
 // Iterator points to a deque item and can be used to iterate through the deque.
+// An iterator is invalidated by any modification of its deque; calling Next or
+// Prev on an invalidated iterator panics with ErrConcurrentModification. The
+// check can be compiled out with the deque_nocheck build tag.
 type Iterator struct {
 	Value interface{}
 
@@ -183,11 +577,15 @@ type Iterator struct {
 	chunk _Chunk        // current chunk (shortcut)
 	i     int           // current item index
 	pos   int           // iteration position
+	mods  int           // deque modification counter at creation
 }
 
 // Next returns an iterator that points to the next deque element, or nil if
 // there is no next element.
 func (it *Iterator) Next() *Iterator {
+	if checkMods && it.mods != it.deque.mods {
+		panic(ErrConcurrentModification)
+	}
 	it.pos++
 	if it.pos >= it.deque.size { // no more items
 		return nil
@@ -195,9 +593,12 @@ func (it *Iterator) Next() *Iterator {
 	it.i++
 	if it.i >= chunkSize { // next chunk?
 		it.node = it.node.Next()
-		it.chunk = it.node.Value.(_Chunk)
+		it.chunk = it.node.Value.(*_Block).chunk
 		it.i = 0
 	}
+	if it.deque.dead > 0 {
+		it.skipDead(1)
+	}
 	it.Value = it.chunk[it.i]
 	return it
 }
@@ -205,6 +606,9 @@ func (it *Iterator) Next() *Iterator {
 // Prev returns an iterator that points to the previous deque element, or nil
 // if there is no previous element.
 func (it *Iterator) Prev() *Iterator {
+	if checkMods && it.mods != it.deque.mods {
+		panic(ErrConcurrentModification)
+	}
 	it.pos--
 	if it.pos < 0 { // no more items
 		return nil
@@ -212,13 +616,97 @@ func (it *Iterator) Prev() *Iterator {
 	it.i--
 	if it.i < 0 { // previous chunk?
 		it.node = it.node.Prev()
-		it.chunk = it.node.Value.(_Chunk)
+		it.chunk = it.node.Value.(*_Block).chunk
 		it.i = chunkSize - 1
 	}
+	if it.deque.dead > 0 {
+		it.skipDead(-1)
+	}
 	it.Value = it.chunk[it.i]
 	return it
 }
 
+// Advance moves the iterator n items towards the back of the deque, or towards
+// the front if n is negative, and returns it. It returns nil, leaving the
+// iterator unchanged, if the target position is outside the deque.
+func (it *Iterator) Advance(n int) *Iterator {
+	if checkMods && it.mods != it.deque.mods {
+		panic(ErrConcurrentModification)
+	}
+	pos := it.pos + n
+	if pos < 0 || pos >= it.deque.size {
+		return nil
+	}
+	it.pos = pos
+	it.node, it.i = it.deque.walk(it.node, it.i, n)
+	it.chunk = it.node.Value.(*_Block).chunk
+	it.Value = it.chunk[it.i]
+	return it
+}
+
+// Pos returns the position of the iterator, which is the index of the item it
+// points to counting from the front of the deque.
+func (it *Iterator) Pos() int {
+	return it.pos
+}
+
+// Distance returns the number of items from the iterator to other, which is
+// negative when other lies towards the front. Both iterators must belong to
+// the same deque.
+func (it *Iterator) Distance(other *Iterator) int {
+	return other.pos - it.pos
+}
+
 //// _Chunk ////////////////////////////////////////////////////////////////////
 
 type _Chunk []interface{}
+
+// _Block holds a chunk in the chunk list. After Clone, the chunks are shared
+// by both deques and copied by the first deque that writes to them.
+type _Block struct {
+	chunk   _Chunk
+	refs    *atomic.Int32        // number of deques sharing chunk, nil if not shared
+	dead    int                  // number of tombstones in chunk
+	handles *[chunkSize]*_Handle // handles by item index, nil if there are none
+}
+
+// isShared reports whether the chunk held by node e may be shared with another
+// deque.
+func isShared(e *list.Element) bool {
+	return e.Value.(*_Block).refs != nil
+}
+
+// own makes the chunk held by node e private to the deque before it is written
+// to, copying it if another deque still shares it.
+func (d *Deque) own(e *list.Element) {
+	b := e.Value.(*_Block)
+	if b.refs == nil {
+		return
+	}
+	if b.refs.Load() > 1 {
+		chunk := make(_Chunk, chunkSize)
+		copy(chunk, b.chunk)
+		b.chunk = chunk
+		b.refs.Add(-1)
+	}
+	b.refs = nil
+	d.shared--
+	if e == d.chunks.Front() {
+		d.fC = b.chunk
+	}
+	if e == d.chunks.Back() {
+		d.bC = b.chunk
+	}
+}
+
+// release gives up the deque's share of the chunk held by node e, before e is
+// removed from the chunk list.
+func (d *Deque) release(e *list.Element) {
+	b := e.Value.(*_Block)
+	if b.refs == nil {
+		return
+	}
+	b.refs.Add(-1)
+	b.refs = nil
+	d.shared--
+}
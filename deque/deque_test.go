@@ -3,9 +3,13 @@
 package deque_test
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/notnot/container/deque"
 )
@@ -169,6 +173,488 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestClear_reuse(t *testing.T) {
+	const N = 100
+	deque := deque.New()
+
+	for i := 0; i < N; i++ {
+		deque.PushFront(i)
+	}
+	deque.Clear()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+	for i := 0; i < N; i++ {
+		if item := deque.PopFront(); item != i {
+			t.Errorf("got: %v, want: %d", item, i)
+		}
+	}
+	if deque.Size() != 0 {
+		t.Errorf("got: %d, want: 0", deque.Size())
+	}
+}
+
+func TestSeek(t *testing.T) {
+	const N = 1000
+	deque := deque.New()
+	for i := 0; i < N/2; i++ {
+		deque.PushBack(i)
+	}
+	for i := -1; i >= -N/2; i-- {
+		deque.PushFront(i)
+	}
+
+	for _, i := range []int{-1, N, N + 1} {
+		if it := deque.Seek(i); it != nil {
+			t.Errorf("got: %v, want: <nil>", it.Value)
+		}
+	}
+	for i := 0; i < N; i++ {
+		it := deque.Seek(i)
+		if it.Value != i-N/2 || it.Pos() != i {
+			t.Fatalf("got: %v at %d, want: %d at %d", it.Value, it.Pos(), i-N/2, i)
+		}
+		if next := it.Next(); next != nil && next.Value != i-N/2+1 {
+			t.Fatalf("got: %v, want: %d", next.Value, i-N/2+1)
+		}
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	const N = 1000
+	deque := deque.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+
+	it := deque.Front()
+	for _, n := range []int{1, 31, 32, 100, -50, -3, 0, 500, -600, 988} {
+		want := it.Pos() + n
+		if it.Advance(n) == nil {
+			t.Fatalf("got: <nil>, want: iterator")
+		}
+		if it.Value != want || it.Pos() != want {
+			t.Fatalf("got: %v at %d, want: %d", it.Value, it.Pos(), want)
+		}
+	}
+	if it.Advance(1) != nil {
+		t.Errorf("got: iterator, want: <nil>")
+	}
+	if it.Pos() != N-1 {
+		t.Errorf("got: %d, want: %d", it.Pos(), N-1)
+	}
+
+	front := deque.Front()
+	if d := front.Distance(it); d != N-1 {
+		t.Errorf("got: %d, want: %d", d, N-1)
+	}
+	if d := it.Distance(front); d != -(N - 1) {
+		t.Errorf("got: %d, want: %d", d, -(N - 1))
+	}
+}
+
+func TestDiscardFront(t *testing.T) {
+	const N = 1000
+	for _, n := range []int{-1, 0, 1, 14, 15, 16, 47, 100, N - 1, N, N + 1} {
+		d := deque.New()
+		for i := 0; i < N; i++ {
+			d.PushBack(i)
+		}
+
+		want := min(max(n, 0), N)
+		if got := d.DiscardFront(n); got != want {
+			t.Errorf("%d: got: %d, want: %d", n, got, want)
+		}
+		if d.Size() != N-want {
+			t.Errorf("%d: got: %d, want: %d", n, d.Size(), N-want)
+		}
+		i := want
+		for it := d.Front(); it != nil; it = it.Next() {
+			if it.Value != i {
+				t.Fatalf("%d: got: %v, want: %d", n, it.Value, i)
+			}
+			i++
+		}
+		d.PushFront("f")
+		if d.PopFront() != "f" {
+			t.Errorf("%d: got: wrong item, want: f", n)
+		}
+	}
+}
+
+func TestIndexFunc(t *testing.T) {
+	const N = 1000
+	deque := deque.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i % 100)
+	}
+	is := func(v int) func(interface{}) bool {
+		return func(item interface{}) bool { return item == v }
+	}
+
+	if i := deque.IndexFunc(is(42)); i != 42 {
+		t.Errorf("got: %d, want: 42", i)
+	}
+	if i := deque.LastIndexFunc(is(42)); i != N-100+42 {
+		t.Errorf("got: %d, want: %d", i, N-100+42)
+	}
+	if i := deque.IndexFunc(is(-1)); i != -1 {
+		t.Errorf("got: %d, want: -1", i)
+	}
+	if i := deque.LastIndexFunc(is(-1)); i != -1 {
+		t.Errorf("got: %d, want: -1", i)
+	}
+	if !deque.ContainsFunc(is(99)) || deque.ContainsFunc(is(100)) {
+		t.Errorf("got: wrong result, want: contains 99 but not 100")
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	const N = 1000
+	odd := func(item interface{}) bool { return item.(int)%2 != 0 }
+
+	for _, n := range []int{0, 1, 2, 31, 32, 33, 100, N} {
+		deque := deque.New()
+		for i := 0; i < n; i++ {
+			if i%3 == 0 {
+				deque.PushFront(-i)
+			} else {
+				deque.PushBack(i)
+			}
+		}
+		want := []interface{}{}
+		for it := deque.Front(); it != nil; it = it.Next() {
+			if !odd(it.Value) {
+				want = append(want, it.Value)
+			}
+		}
+
+		if deleted := deque.DeleteFunc(odd); deleted != n-len(want) {
+			t.Errorf("%d: got: %d, want: %d", n, deleted, n-len(want))
+		}
+		if deque.Size() != len(want) {
+			t.Errorf("%d: got: %d, want: %d", n, deque.Size(), len(want))
+		}
+		i := 0
+		for it := deque.Front(); it != nil; it = it.Next() {
+			if it.Value != want[i] {
+				t.Errorf("%d: got: %v, want: %v", n, it.Value, want[i])
+			}
+			i++
+		}
+
+		// the deque must remain usable at both ends
+		deque.PushBack("b")
+		deque.PushFront("f")
+		if deque.PopBack() != "b" || deque.PopFront() != "f" {
+			t.Errorf("%d: got: wrong items, want: f and b", n)
+		}
+		if deque.DeleteFunc(func(interface{}) bool { return true }) != len(want) {
+			t.Errorf("%d: got: wrong count, want: %d", n, len(want))
+		}
+		if deque.Size() != 0 || deque.Front() != nil {
+			t.Errorf("%d: got: %d, want: 0", n, deque.Size())
+		}
+		deque.PushBack(1)
+		if deque.PopFront() != 1 {
+			t.Errorf("%d: got: wrong item, want: 1", n)
+		}
+	}
+}
+
+func TestMapFilter(t *testing.T) {
+	for _, n := range []int{0, 1, 31, 32, 33, 1000} {
+		d := deque.New()
+		for i := 0; i < n; i++ {
+			d.PushBack(i)
+		}
+
+		squares := deque.Map(d, func(item interface{}) interface{} { return item.(int) * item.(int) })
+		if squares.Size() != n {
+			t.Errorf("%d: got: %d, want: %d", n, squares.Size(), n)
+		}
+		i := 0
+		for it := squares.Front(); it != nil; it = it.Next() {
+			if it.Value != i*i {
+				t.Errorf("%d: got: %v, want: %d", n, it.Value, i*i)
+			}
+			i++
+		}
+
+		even := func(item interface{}) bool { return item.(int)%2 == 0 }
+		evens := deque.Filter(d, even)
+		yes, no := deque.Partition(d, even)
+		if evens.Size() != (n+1)/2 || yes.Size() != (n+1)/2 || no.Size() != n/2 {
+			t.Errorf("%d: got: %d %d %d, want: %d %d %d", n,
+				evens.Size(), yes.Size(), no.Size(), (n+1)/2, (n+1)/2, n/2)
+		}
+		for i := 0; evens.Size() > 0; i += 2 {
+			if item := evens.PopFront(); item != i {
+				t.Errorf("%d: got: %v, want: %d", n, item, i)
+			}
+			if item := yes.PopFront(); item != i {
+				t.Errorf("%d: got: %v, want: %d", n, item, i)
+			}
+		}
+		for i := n - 1 - n%2; no.Size() > 0; i -= 2 {
+			if item := no.PopBack(); item != i {
+				t.Errorf("%d: got: %v, want: %d", n, item, i)
+			}
+		}
+
+		// the built deques must remain usable at both ends
+		squares.PushFront("f")
+		squares.PushBack("b")
+		if squares.PopFront() != "f" || squares.PopBack() != "b" {
+			t.Errorf("%d: got: wrong items, want: f and b", n)
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	const N = 100
+	d := deque.New()
+	for i := 1; i <= N; i++ {
+		d.PushBack(i)
+	}
+
+	sum := deque.Reduce(d, 0, func(acc, item interface{}) interface{} { return acc.(int) + item.(int) })
+	if sum != N*(N+1)/2 {
+		t.Errorf("got: %v, want: %d", sum, N*(N+1)/2)
+	}
+	if got := deque.Reduce(deque.New(), "init", nil); got != "init" {
+		t.Errorf("got: %v, want: init", got)
+	}
+}
+
+func TestEqualCompare(t *testing.T) {
+	const N = 1000
+	a, b := deque.New(), deque.New()
+	for i := 0; i < N; i++ {
+		a.PushBack(i)
+	}
+	for i := N - 1; i >= 0; i-- { // different chunk alignment
+		b.PushFront(i)
+	}
+	cmpInt := func(x, y interface{}) int { return x.(int) - y.(int) }
+
+	if !deque.Equal(a, b) || deque.Compare(a, b, cmpInt) != 0 {
+		t.Errorf("got: a != b, want: a == b")
+	}
+	if !deque.Equal(deque.New(), deque.New()) {
+		t.Errorf("got: empty != empty, want: empty == empty")
+	}
+
+	b.PopBack()
+	if deque.Equal(a, b) {
+		t.Errorf("got: a == b, want: a != b")
+	}
+	if c := deque.Compare(a, b, cmpInt); c != +1 {
+		t.Errorf("got: %d, want: +1", c)
+	}
+	if c := deque.Compare(b, a, cmpInt); c != -1 {
+		t.Errorf("got: %d, want: -1", c)
+	}
+
+	b.PushBack(N)
+	if deque.Equal(a, b) {
+		t.Errorf("got: a == b, want: a != b")
+	}
+	if c := deque.Compare(a, b, cmpInt); c != -1 {
+		t.Errorf("got: %d, want: -1", c)
+	}
+	sameParity := func(x, y interface{}) bool { return x.(int)%2 == y.(int)%2 }
+	if deque.EqualFunc(a, b, sameParity) {
+		t.Errorf("got: equal parity, want: different parity")
+	}
+}
+
+func TestClone(t *testing.T) {
+	for _, n := range []int{0, 1, 33, 1000} {
+		d := deque.New()
+		for i := 0; i < n; i++ {
+			d.PushFront(i)
+		}
+
+		c := d.Clone()
+		if !deque.Equal(c, d) {
+			t.Errorf("%d: got: clone != original, want: clone == original", n)
+		}
+		c.PushFront("f")
+		c.PushBack("b")
+		if c.PopFront() != "f" || c.PopBack() != "b" {
+			t.Errorf("%d: got: wrong items, want: f and b", n)
+		}
+		for c.Size() > n/2 {
+			c.PopFront()
+		}
+
+		// the original must be unaffected
+		i := n - 1
+		for it := d.Front(); it != nil; it = it.Next() {
+			if it.Value != i {
+				t.Errorf("%d: got: %v, want: %d", n, it.Value, i)
+			}
+			i--
+		}
+		if i != -1 {
+			t.Errorf("%d: got: %d items, want: %d", n, n-1-i, n)
+		}
+	}
+}
+
+// TestClone_copyOnWrite modifies a deque and its clones, which share chunks,
+// and checks each of them against its own model.
+func TestClone_copyOnWrite(t *testing.T) {
+	const N = 2000
+	type pair struct {
+		deque *deque.Deque
+		model []interface{}
+	}
+	pairs := []*pair{{deque: deque.New()}}
+
+	for i := 0; i < N; i++ {
+		p := pairs[rand.Intn(len(pairs))]
+		switch op := rand.Intn(100); {
+		case op < 35:
+			p.deque.PushFront(i)
+			p.model = append([]interface{}{i}, p.model...)
+		case op < 70:
+			p.deque.PushBack(i)
+			p.model = append(p.model, i)
+		case op < 80:
+			if p.deque.PopFront() != nil {
+				p.model = p.model[1:]
+			}
+		case op < 90:
+			if p.deque.PopBack() != nil {
+				p.model = p.model[:len(p.model)-1]
+			}
+		case op < 92:
+			p.deque.DeleteFunc(func(item interface{}) bool { return item.(int)%3 == 0 })
+			model := []interface{}{}
+			for _, item := range p.model {
+				if item.(int)%3 != 0 {
+					model = append(model, item)
+				}
+			}
+			p.model = model
+		case op < 93:
+			p.deque.Clear()
+			p.model = nil
+		default:
+			c := &pair{deque: p.deque.Clone()}
+			c.model = append(c.model, p.model...)
+			pairs = append(pairs, c)
+		}
+	}
+
+	for k, p := range pairs {
+		if p.deque.Size() != len(p.model) {
+			t.Fatalf("%d: got: %d, want: %d", k, p.deque.Size(), len(p.model))
+		}
+		i := 0
+		for it := p.deque.Front(); it != nil; it = it.Next() {
+			if it.Value != p.model[i] {
+				t.Fatalf("%d: got: %v, want: %v", k, it.Value, p.model[i])
+			}
+			i++
+		}
+	}
+
+	// popping a deque empty leaves no items behind in chunks shared with a
+	// clone
+	d := deque.New()
+	for i := 0; i < 5; i++ {
+		d.PushBack(i)
+	}
+	c := d.Clone()
+	for i := 0; i < 5; i++ {
+		d.PopFront()
+	}
+	if d.FrontItem() != nil || d.BackItem() != nil {
+		t.Errorf("got: %v %v, want: <nil> <nil>", d.FrontItem(), d.BackItem())
+	}
+	if c.FrontItem() != 0 || c.BackItem() != 4 {
+		t.Errorf("got: %v %v, want: 0 4", c.FrontItem(), c.BackItem())
+	}
+}
+
+// TestClone_concurrentRead reads a clone while the original is modified; run
+// with -race.
+func TestClone_concurrentRead(t *testing.T) {
+	const N = 1000
+	d := deque.New()
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+	}
+	snapshot := d.Clone()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < N; i++ {
+			d.PopFront()
+			d.PushBack(-i)
+			d.PushFront(-i)
+			d.PopBack()
+		}
+	}()
+	for k := 0; k < 10; k++ {
+		i := 0
+		for it := snapshot.Front(); it != nil; it = it.Next() {
+			if it.Value != i {
+				t.Errorf("got: %v, want: %d", it.Value, i)
+			}
+			i++
+		}
+	}
+	<-done
+}
+
+func TestFormat(t *testing.T) {
+	d := deque.New()
+	for i := 0; i < 3; i++ {
+		d.PushBack(i)
+	}
+	var empty *deque.Deque
+
+	for _, test := range []struct {
+		format string
+		arg    interface{}
+		want   string
+	}{
+		{"%v", d, "[0 1 2]"},
+		{"%d", d, "[0 1 2]"},
+		{"%03d", d, "[000 001 002]"},
+		{"%+v", d, "[0 1 2] (len=3, chunks=1)"},
+		{"%v", deque.New(), "[]"},
+		{"%v", empty, "<nil>"},
+		{"%v", d.Back(), "2"},
+		{"%+v", d.Front().Next(), "1 (pos=1, len=3)"},
+	} {
+		if got := fmt.Sprintf(test.format, test.arg); got != test.want {
+			t.Errorf("%s: got: %s, want: %s", test.format, got, test.want)
+		}
+	}
+	if d.String() != "[0 1 2]" {
+		t.Errorf("got: %s, want: [0 1 2]", d.String())
+	}
+	if d.Front().String() != "0" {
+		t.Errorf("got: %s, want: 0", d.Front().String())
+	}
+
+	for i := 3; i < 10000; i++ {
+		d.PushBack(i)
+	}
+	if got, want := d.String(), "[0 1 2 ... 9997 9998 9999] (len=10000)"; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	if got, want := fmt.Sprintf("%+v", d), "[0 1 2 ... 9997 9998 9999] (len=10000, chunks=314)"; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
 func TestIterate(t *testing.T) {
 	const N = 1000
 	deque := deque.New()
@@ -195,6 +681,542 @@ func TestIterate(t *testing.T) {
 	}
 }
 
+func TestChunks(t *testing.T) {
+	const N = 1000
+	deque := deque.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+	for i := 1; i <= N/2; i++ {
+		deque.PushFront(-i)
+	}
+
+	// walk the chunks from front to back
+	want := -N / 2
+	for chunk := range deque.Chunks() {
+		for _, item := range chunk {
+			if item.(int) != want {
+				t.Errorf("got: %v, want: %d", item, want)
+			}
+			want++
+		}
+	}
+	if want != N {
+		t.Errorf("got: %d items, want: %d", want+N/2, N+N/2)
+	}
+
+	// walk the chunks from back to front
+	want = N - 1
+	for chunk := range deque.ChunksBackward() {
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i].(int) != want {
+				t.Errorf("got: %v, want: %d", chunk[i], want)
+			}
+			want--
+		}
+	}
+	if want != -N/2-1 {
+		t.Errorf("got: %d items, want: %d", N-1-want, N+N/2)
+	}
+}
+
+func TestChunks_empty(t *testing.T) {
+	deque := deque.New()
+	for chunk := range deque.Chunks() {
+		t.Errorf("got: %v, want: no chunks", chunk)
+	}
+
+	deque.PushBack(1)
+	deque.PopFront()
+	for chunk := range deque.ChunksBackward() {
+		t.Errorf("got: %v, want: no chunks", chunk)
+	}
+}
+
+func TestStack(t *testing.T) {
+	const N = 100
+	stack := deque.NewStack[int]()
+
+	if _, ok := stack.Pop(); ok {
+		t.Errorf("got: ok, want: !ok")
+	}
+	if _, ok := stack.Peek(); ok {
+		t.Errorf("got: ok, want: !ok")
+	}
+	for i := 0; i < N; i++ {
+		stack.Push(i)
+	}
+	if stack.Size() != N {
+		t.Errorf("got: %d, want: %d", stack.Size(), N)
+	}
+	for i := N - 1; i >= 0; i-- {
+		if item, ok := stack.Peek(); item != i || !ok {
+			t.Errorf("got: %d, %v, want: %d, true", item, ok, i)
+		}
+		if item, ok := stack.Pop(); item != i || !ok {
+			t.Errorf("got: %d, %v, want: %d, true", item, ok, i)
+		}
+	}
+
+	errs := deque.NewStack[error]()
+	errs.Push(nil)
+	if err, ok := errs.Pop(); err != nil || !ok {
+		t.Errorf("got: %v, %v, want: <nil>, true", err, ok)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	const N = 100
+	queue := deque.NewQueue[string]()
+
+	if _, ok := queue.Dequeue(); ok {
+		t.Errorf("got: ok, want: !ok")
+	}
+	if _, ok := queue.Peek(); ok {
+		t.Errorf("got: ok, want: !ok")
+	}
+	for i := 0; i < N; i++ {
+		queue.Enqueue(fmt.Sprint(i))
+	}
+	if queue.Size() != N {
+		t.Errorf("got: %d, want: %d", queue.Size(), N)
+	}
+	for i := 0; i < N; i++ {
+		want := fmt.Sprint(i)
+		if item, ok := queue.Peek(); item != want || !ok {
+			t.Errorf("got: %s, %v, want: %s, true", item, ok, want)
+		}
+		if item, ok := queue.Dequeue(); item != want || !ok {
+			t.Errorf("got: %s, %v, want: %s, true", item, ok, want)
+		}
+	}
+	queue.Enqueue("x")
+	queue.Clear()
+	if queue.Size() != 0 {
+		t.Errorf("got: %d, want: 0", queue.Size())
+	}
+}
+
+func TestUnboundedChan(t *testing.T) {
+	const N = 1000
+	c := deque.NewUnbounded[int]()
+
+	for i := 0; i < N; i++ { // no receiver yet, must not block
+		c.In <- i
+	}
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != N && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if c.Len() != N {
+		t.Errorf("got: %d, want: %d", c.Len(), N)
+	}
+
+	close(c.In)
+	i := 0
+	for item := range c.Out { // buffered items survive the close
+		if item != i {
+			t.Fatalf("got: %d, want: %d", item, i)
+		}
+		i++
+	}
+	if i != N || c.Len() != 0 {
+		t.Errorf("got: %d received, %d buffered, want: %d, 0", i, c.Len(), N)
+	}
+}
+
+func TestUnboundedChan_concurrent(t *testing.T) {
+	const P, N = 4, 1000
+	in, out := deque.NewUnboundedChan[error]()
+
+	var wg sync.WaitGroup
+	for p := 0; p < P; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				in <- nil // nil interface items pass through
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(in)
+	}()
+
+	n := 0
+	for err := range out {
+		if err != nil {
+			t.Fatalf("got: %v, want: <nil>", err)
+		}
+		n++
+	}
+	if n != P*N {
+		t.Errorf("got: %d, want: %d", n, P*N)
+	}
+}
+
+// counter is an observer that keeps running totals of the deque events.
+type counter struct {
+	items, chunks int // as derived from the events
+	size, nchunks int // as reported by the events
+}
+
+func (c *counter) Pushed(n, size int)   { c.items += n; c.size = size }
+func (c *counter) Popped(n, size int)   { c.items -= n; c.size = size }
+func (c *counter) Grew(n, chunks int)   { c.chunks += n; c.nchunks = chunks }
+func (c *counter) Shrank(n, chunks int) { c.chunks -= n; c.nchunks = chunks }
+
+func TestObserver(t *testing.T) {
+	d := deque.New()
+	c := &counter{chunks: 1, nchunks: 1} // a new deque has one chunk
+	d.SetObserver(c)
+
+	check := func(op string) {
+		t.Helper()
+		if c.items != d.Size() || c.size != d.Size() {
+			t.Fatalf("%s: got: %d items, size %d, want: %d", op, c.items, c.size, d.Size())
+		}
+		if c.chunks != c.nchunks {
+			t.Fatalf("%s: got: %d chunks, reported %d", op, c.chunks, c.nchunks)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		switch rand.Intn(10) {
+		case 0, 1, 2:
+			d.PushFront(i)
+			check("PushFront")
+		case 3, 4, 5:
+			d.PushBack(i)
+			check("PushBack")
+		case 6:
+			d.PopFront()
+			check("PopFront")
+		case 7:
+			d.PopBack()
+			check("PopBack")
+		case 8:
+			d.DiscardFront(rand.Intn(50))
+			check("DiscardFront")
+		case 9:
+			if rand.Intn(20) == 0 {
+				d.Clear()
+				check("Clear")
+			} else {
+				d.DeleteFunc(func(item interface{}) bool { return item.(int)%7 == 0 })
+				check("DeleteFunc")
+			}
+		}
+	}
+
+	d.SetObserver(nil)
+	d.PushBack(0)
+	if c.items == d.Size() {
+		t.Errorf("got: event, want: detached observer")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	d := deque.New()
+	var zero deque.Handle
+	if d.RemoveHandle(zero) {
+		t.Errorf("got: removed, want: invalid handle")
+	}
+
+	handles := make([]deque.Handle, 100)
+	for i := range handles {
+		handles[i] = d.PushBackHandle(i)
+	}
+	for i := 1; i < 99; i += 2 { // leave tombstones inside the deque
+		if !d.RemoveHandle(handles[i]) {
+			t.Errorf("got: not removed, want: %d removed", i)
+		}
+	}
+	if d.RemoveHandle(handles[1]) {
+		t.Errorf("got: removed twice, want: once")
+	}
+	if d.Size() != 51 {
+		t.Errorf("got: %d, want: 51", d.Size())
+	}
+	want := 0
+	for it := d.Front(); it != nil; it = it.Next() {
+		if it.Value != want {
+			t.Fatalf("got: %v, want: %d", it.Value, want)
+		}
+		if want < 98 {
+			want += 2
+		} else {
+			want = 99
+		}
+	}
+	if it := d.Seek(50); it == nil || it.Value != 99 {
+		t.Errorf("got: %v, want: 99", it)
+	}
+
+	// handles follow their items through compaction and DeleteFunc
+	d.DeleteFunc(func(item interface{}) bool { return item.(int)%4 == 0 })
+	if !d.RemoveHandle(handles[2]) || !d.RemoveHandle(handles[99]) {
+		t.Errorf("got: not removed, want: 2 and 99 removed")
+	}
+	if d.RemoveHandle(handles[4]) || d.RemoveHandle(handles[0]) {
+		t.Errorf("got: removed, want: deleted items gone")
+	}
+	other := deque.New()
+	h := other.PushBackHandle(0)
+	if d.RemoveHandle(h) {
+		t.Errorf("got: removed, want: handle of another deque")
+	}
+}
+
+// TestHandle_model checks a deque with tombstones against a slice.
+func TestHandle_model(t *testing.T) {
+	type entry struct {
+		item   int
+		handle deque.Handle
+	}
+	d := deque.New()
+	c := &counter{chunks: 1, nchunks: 1}
+	d.SetObserver(c)
+	model := []entry{}
+	removed := []deque.Handle{}
+
+	check := func(op string) {
+		t.Helper()
+		if d.Size() != len(model) || c.items != len(model) {
+			t.Fatalf("%s: got: %d (%d observed), want: %d", op, d.Size(), c.items, len(model))
+		}
+		i := 0
+		for chunk := range d.Chunks() {
+			for _, item := range chunk {
+				if item != model[i].item {
+					t.Fatalf("%s: chunks[%d]: got: %v, want: %d", op, i, item, model[i].item)
+				}
+				i++
+			}
+		}
+		if i != len(model) {
+			t.Fatalf("%s: got: %d chunk items, want: %d", op, i, len(model))
+		}
+		i = len(model)
+		for chunk := range d.ChunksBackward() {
+			i -= len(chunk)
+			for j, item := range chunk {
+				if item != model[i+j].item {
+					t.Fatalf("%s: backward[%d]: got: %v, want: %d", op, i+j, item, model[i+j].item)
+				}
+			}
+		}
+		i = len(model) - 1
+		for it := d.Back(); it != nil; it = it.Prev() {
+			if it.Value != model[i].item {
+				t.Fatalf("%s: back[%d]: got: %v, want: %d", op, i, it.Value, model[i].item)
+			}
+			i--
+		}
+		if len(model) > 0 {
+			if d.FrontItem() != model[0].item || d.BackItem() != model[len(model)-1].item {
+				t.Fatalf("%s: got: ends %v, %v", op, d.FrontItem(), d.BackItem())
+			}
+			j := rand.Intn(len(model))
+			it := d.Seek(j)
+			if it.Value != model[j].item {
+				t.Fatalf("%s: seek %d: got: %v, want: %d", op, j, it.Value, model[j].item)
+			}
+			k := rand.Intn(len(model))
+			if it.Advance(k-j).Value != model[k].item {
+				t.Fatalf("%s: advance %d: got: %v, want: %d", op, k-j, it.Value, model[k].item)
+			}
+		}
+	}
+
+	for n := 0; n < 20000; n++ {
+		switch r := rand.Intn(100); {
+		case r < 30:
+			model = append(model, entry{n, d.PushBackHandle(n)})
+			check("PushBackHandle")
+		case r < 45:
+			model = append([]entry{{n, d.PushFrontHandle(n)}}, model...)
+			check("PushFrontHandle")
+		case r < 50:
+			d.PushBack(n)
+			model = append(model, entry{item: n})
+			check("PushBack")
+		case r < 80:
+			if len(model) == 0 {
+				continue
+			}
+			i := rand.Intn(len(model))
+			h := model[i].handle
+			if h == (deque.Handle{}) {
+				continue
+			}
+			if !d.RemoveHandle(h) {
+				t.Fatalf("got: not removed, want: %d removed", model[i].item)
+			}
+			removed = append(removed, h)
+			model = append(model[:i], model[i+1:]...)
+			check("RemoveHandle")
+		case r < 85:
+			if len(model) > 0 {
+				d.PopFront()
+				removed = append(removed, model[0].handle)
+				model = model[1:]
+			}
+			check("PopFront")
+		case r < 90:
+			if len(model) > 0 {
+				d.PopBack()
+				removed = append(removed, model[len(model)-1].handle)
+				model = model[:len(model)-1]
+			}
+			check("PopBack")
+		case r < 94:
+			k := rand.Intn(40)
+			d.DiscardFront(k)
+			k = min(k, len(model))
+			for _, e := range model[:k] {
+				removed = append(removed, e.handle)
+			}
+			model = model[k:]
+			check("DiscardFront")
+		case r < 97:
+			m := 2 + rand.Intn(8)
+			d.DeleteFunc(func(item interface{}) bool { return item.(int)%m == 0 })
+			kept := model[:0]
+			for _, e := range model {
+				if e.item%m == 0 {
+					removed = append(removed, e.handle)
+				} else {
+					kept = append(kept, e)
+				}
+			}
+			model = kept
+			check("DeleteFunc")
+		case r < 99:
+			clone := d.Clone()
+			if !deque.Equal(clone, d) {
+				t.Fatalf("got: %v, want: %v", clone, d)
+			}
+			if len(model) > 2 { // the original writes a tombstone to a shared chunk
+				i := 1 + rand.Intn(len(model)-2)
+				if h := model[i].handle; h != (deque.Handle{}) && d.RemoveHandle(h) {
+					removed = append(removed, h)
+					model = append(model[:i], model[i+1:]...)
+					if clone.Size() != d.Size()+1 {
+						t.Fatalf("got: %d, want: %d", clone.Size(), d.Size()+1)
+					}
+				}
+			}
+			check("Clone")
+		default:
+			if rand.Intn(4) == 0 {
+				d.Clear()
+				for _, e := range model {
+					removed = append(removed, e.handle)
+				}
+				model = model[:0]
+				check("Clear")
+			}
+		}
+		if c.chunks != c.nchunks {
+			t.Fatalf("got: %d chunks, reported %d", c.chunks, c.nchunks)
+		}
+	}
+	for _, h := range removed {
+		if d.RemoveHandle(h) {
+			t.Fatalf("got: removed, want: stale handle")
+		}
+	}
+}
+
+func TestFromChan(t *testing.T) {
+	const N = 100
+	ch := make(chan int)
+	go func() {
+		for i := 0; i < N; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	d := deque.FromChan(context.Background(), ch)
+	if d.Size() != N {
+		t.Fatalf("got: %d, want: %d", d.Size(), N)
+	}
+	for i := 0; i < N; i++ {
+		if item := d.PopFront(); item != i {
+			t.Errorf("got: %v, want: %d", item, i)
+		}
+	}
+
+	// a cancelled context stops the collection
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if d := deque.FromChan(ctx, make(chan int)); d.Size() != 0 {
+		t.Errorf("got: %d, want: 0", d.Size())
+	}
+}
+
+func TestPushBackFromChan(t *testing.T) {
+	ch := make(chan string, 10)
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		ch <- s
+	}
+	close(ch)
+
+	d := deque.New()
+	d.PushBack("x")
+	if n, err := deque.PushBackFromChan(context.Background(), d, ch, 3); n != 3 || err != nil {
+		t.Errorf("got: %d, %v, want: 3, <nil>", n, err)
+	}
+	if n, err := deque.PushBackFromChan(context.Background(), d, ch, 3); n != 2 || err != io.EOF {
+		t.Errorf("got: %d, %v, want: 2, EOF", n, err)
+	}
+	if s := d.String(); s != "[x a b c d e]" {
+		t.Errorf("got: %s, want: [x a b c d e]", s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	n, err := deque.PushBackFromChan(ctx, d, make(chan string), -1)
+	if n != 0 || err != context.DeadlineExceeded {
+		t.Errorf("got: %d, %v, want: 0, %v", n, err, context.DeadlineExceeded)
+	}
+}
+
+func TestDrainTo(t *testing.T) {
+	const N = 100
+	d := deque.New()
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+	}
+
+	// the consumer takes half of the items, then stops
+	ch := make(chan interface{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for i := 0; i < N/2; i++ {
+			<-ch
+		}
+		cancel()
+	}()
+	n, err := d.DrainTo(ctx, ch)
+	if n != N/2 || err != context.Canceled {
+		t.Errorf("got: %d, %v, want: %d, %v", n, err, N/2, context.Canceled)
+	}
+	if d.Size() != N/2 || d.FrontItem() != N/2 { // unsent items stay
+		t.Errorf("got: %d from %v, want: %d from %d", d.Size(), d.FrontItem(), N/2, N/2)
+	}
+
+	buf := make(chan interface{}, N)
+	if n, err := d.DrainTo(context.Background(), buf); n != N/2 || err != nil {
+		t.Errorf("got: %d, %v, want: %d, <nil>", n, err, N/2)
+	}
+	if d.Size() != 0 || len(buf) != N/2 {
+		t.Errorf("got: %d left, %d sent, want: 0, %d", d.Size(), len(buf), N/2)
+	}
+}
+
 //// benchmarks ////////////////////////////////////////////////////////////////
 
 func BenchmarkPushPopFront_10(b *testing.B) {
@@ -337,6 +1359,49 @@ func BenchmarkIterate_backward(b *testing.B) {
 	}
 }
 
+func BenchmarkIterate_chunks(b *testing.B) {
+	const N = 1024
+	deque := deque.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for chunk := range deque.Chunks() {
+			for _, item := range chunk {
+				_ = item
+			}
+		}
+	}
+}
+
+func BenchmarkMap_1000(b *testing.B) {
+	const N = 1000
+	d := deque.New()
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		deque.Map(d, func(item interface{}) interface{} { return item })
+	}
+}
+
+func BenchmarkClone_1000(b *testing.B) {
+	const N = 1000
+	d := deque.New()
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d.Clone()
+	}
+}
+
 //// examples //////////////////////////////////////////////////////////////////
 
 func ExampleIterator() {
@@ -362,3 +1427,36 @@ func ExampleIterator() {
 	// 0123456789
 	// 9876543210
 }
+
+func ExampleIterator_Advance() {
+	const N = 100
+	deque := deque.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+
+	// visit every 25th item, starting at index 10
+	for it := deque.Seek(10); it != nil; it = it.Advance(25) {
+		fmt.Printf("%v@%d ", it.Value, it.Pos())
+	}
+	fmt.Println()
+
+	// Output:
+	// 10@10 35@35 60@60 85@85
+}
+
+func ExampleDeque_Format() {
+	deque := deque.New()
+	for _, item := range []string{"a", "b", "c"} {
+		deque.PushBack(item)
+	}
+
+	fmt.Printf("%v\n", deque)
+	fmt.Printf("%q\n", deque)
+	fmt.Printf("%+v\n", deque)
+
+	// Output:
+	// [a b c]
+	// ["a" "b" "c"]
+	// [a b c] (len=3, chunks=1)
+}
@@ -0,0 +1,75 @@
+// format.go, jpad 2026
+
+package deque
+
+import (
+	"fmt"
+)
+
+const (
+	formatMax  = 16 // larger deques are elided when formatted
+	formatEdge = 3  // number of items shown at either end of an elided deque
+)
+
+// String returns the items of the deque formatted as by Format with the %v
+// verb.
+func (d *Deque) String() string {
+	return fmt.Sprintf("%v", d)
+}
+
+// Format implements fmt.Formatter. The items are written between brackets and
+// formatted with the verb and flags of the call, so %v prints [a b c]. Deques
+// of more than 16 items are elided to their first and last three items,
+// followed by the size: [a b c ... x y z] (len=10000). The + flag adds the
+// size and number of chunks in any case.
+func (d *Deque) Format(s fmt.State, verb rune) {
+	if d == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+	format := fmt.FormatString(s, verb)
+	elide := d.size > formatMax
+
+	s.Write([]byte("["))
+	i := 0
+	for it := d.Front(); it != nil; it = it.Next() {
+		if elide && i == formatEdge {
+			s.Write([]byte(" ..."))
+			it = d.Seek(d.size - formatEdge)
+			i = d.size - formatEdge
+		}
+		if i > 0 {
+			s.Write([]byte(" "))
+		}
+		fmt.Fprintf(s, format, it.Value)
+		i++
+	}
+	s.Write([]byte("]"))
+
+	switch {
+	case s.Flag('+'):
+		fmt.Fprintf(s, " (len=%d, chunks=%d)", d.size, d.chunks.Len())
+	case elide:
+		fmt.Fprintf(s, " (len=%d)", d.size)
+	}
+}
+
+// String returns the value of the iterator formatted as by Format with the %v
+// verb.
+func (it *Iterator) String() string {
+	return fmt.Sprintf("%v", it)
+}
+
+// Format implements fmt.Formatter. The value of the iterator is formatted with
+// the verb and flags of the call. The + flag adds the position of the iterator
+// and the size of its deque: x (pos=3, len=10).
+func (it *Iterator) Format(s fmt.State, verb rune) {
+	if it == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+	fmt.Fprintf(s, fmt.FormatString(s, verb), it.Value)
+	if s.Flag('+') {
+		fmt.Fprintf(s, " (pos=%d, len=%d)", it.pos, it.deque.size)
+	}
+}
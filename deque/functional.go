@@ -0,0 +1,114 @@
+// functional.go, jpad 2026
+
+package deque
+
+import (
+	"container/list"
+)
+
+// Map returns a new deque holding the results of f applied to the items of d,
+// in the same order.
+func Map(d *Deque, f func(item interface{}) interface{}) *Deque {
+	out := newFiller(d.size)
+	for chunk := range d.Chunks() {
+		for _, item := range chunk {
+			out.push(f(item))
+		}
+	}
+	return out.done()
+}
+
+// Filter returns a new deque holding the items of d that satisfy pred, in the
+// same order.
+func Filter(d *Deque, pred func(item interface{}) bool) *Deque {
+	out := newFiller(0)
+	for chunk := range d.Chunks() {
+		for _, item := range chunk {
+			if pred(item) {
+				out.push(item)
+			}
+		}
+	}
+	return out.done()
+}
+
+// Partition returns two new deques, holding the items of d that do and do not
+// satisfy pred respectively, in the same order.
+func Partition(d *Deque, pred func(item interface{}) bool) (yes, no *Deque) {
+	y, n := newFiller(0), newFiller(0)
+	for chunk := range d.Chunks() {
+		for _, item := range chunk {
+			if pred(item) {
+				y.push(item)
+			} else {
+				n.push(item)
+			}
+		}
+	}
+	return y.done(), n.done()
+}
+
+// Reduce combines the items of d from front to back into a single value, by
+// applying f to the accumulated value, starting at init, and each item.
+func Reduce(d *Deque, init interface{}, f func(acc, item interface{}) interface{}) interface{} {
+	acc := init
+	for chunk := range d.Chunks() {
+		for _, item := range chunk {
+			acc = f(acc, item)
+		}
+	}
+	return acc
+}
+
+//// filler ////////////////////////////////////////////////////////////////////
+
+// filler builds a new deque from front to back. Chunks are filled from their
+// first slot on, instead of from the center, and can be reserved up front.
+type filler struct {
+	deque *Deque
+	node  *list.Element // chunk node being filled
+	chunk _Chunk        // chunk being filled (shortcut)
+	i     int           // next item index
+}
+
+// newFiller returns a filler with chunks reserved for n items.
+func newFiller(n int) *filler {
+	d := &Deque{}
+	d.chunks.Init()
+	for k := 0; k == 0 || k*chunkSize < n; k++ {
+		d.chunks.PushBack(&_Block{chunk: make(_Chunk, chunkSize)})
+	}
+	node := d.chunks.Front()
+	return &filler{deque: d, node: node, chunk: node.Value.(*_Block).chunk}
+}
+
+func (f *filler) push(item interface{}) {
+	if f.i == chunkSize { // next chunk?
+		next := f.node.Next()
+		if next == nil {
+			next = f.deque.chunks.PushBack(&_Block{chunk: make(_Chunk, chunkSize)})
+		}
+		f.node = next
+		f.chunk = next.Value.(*_Block).chunk
+		f.i = 0
+	}
+	f.chunk[f.i] = item
+	f.i++
+	f.deque.size++
+}
+
+// done releases the unused reserved chunks and returns the deque.
+func (f *filler) done() *Deque {
+	d := f.deque
+	for d.chunks.Back() != f.node {
+		d.chunks.Remove(d.chunks.Back())
+	}
+	d.fC = d.chunks.Front().Value.(*_Block).chunk
+	d.fI = 0
+	d.bC = f.chunk
+	d.bI = f.i - 1
+	if d.size == 0 { // deque is empty, reset it
+		d.reset()
+	}
+	return d
+}
@@ -0,0 +1,356 @@
+// handle.go, jpad 2026
+
+package deque
+
+import (
+	"container/list"
+)
+
+//// Handle ////////////////////////////////////////////////////////////////////
+
+// Handle refers to an item pushed with PushFrontHandle or PushBackHandle, and
+// stays valid while the item is in the deque, wherever the item moves. The
+// zero Handle refers to no item.
+type Handle struct {
+	h *_Handle
+}
+
+type _Handle struct {
+	deque *Deque        // nil once the item has left the deque
+	node  *list.Element // chunk node holding the item
+	i     int           // item index in the chunk
+}
+
+// _Tombstone takes the place of an item removed with RemoveHandle, until the
+// chunk holding it is compacted or the tombstone reaches an end of the deque.
+type _Tombstone struct{}
+
+func isDead(item interface{}) bool {
+	_, dead := item.(_Tombstone)
+	return dead
+}
+
+// PushFrontHandle adds an item to the front of the deque and returns a handle
+// to it.
+func (d *Deque) PushFrontHandle(item interface{}) Handle {
+	d.PushFront(item)
+	return d.newHandle(d.chunks.Front(), d.fI)
+}
+
+// PushBackHandle adds an item to the back of the deque and returns a handle to
+// it.
+func (d *Deque) PushBackHandle(item interface{}) Handle {
+	d.PushBack(item)
+	return d.newHandle(d.chunks.Back(), d.bI)
+}
+
+// RemoveHandle removes the item that h refers to from the deque in constant
+// time, and reports whether the item was still in the deque. An item inside
+// the deque leaves a tombstone behind, which iteration skips. A chunk that
+// becomes mostly tombstones is merged with a neighbor when their items fit in
+// a single chunk.
+func (d *Deque) RemoveHandle(h Handle) bool {
+	r := h.h
+	if r == nil || r.deque != d {
+		return false
+	}
+	e, i := r.node, r.i
+	if e == d.chunks.Front() && i == d.fI {
+		d.PopFront()
+		return true
+	}
+	if e == d.chunks.Back() && i == d.bI {
+		d.PopBack()
+		return true
+	}
+
+	if d.shared > 0 {
+		d.own(e)
+	}
+	d.unhandle(e, i)
+	b := e.Value.(*_Block)
+	b.chunk[i] = _Tombstone{}
+	b.dead++
+	d.dead++
+	d.size--
+	d.mods++
+	if b.dead > chunkCenter { // mostly tombstones?
+		d.compact(e)
+	}
+	if d.obs != nil {
+		d.obs.Popped(1, d.size)
+	}
+	return true
+}
+
+// newHandle returns a new handle to the item at index i in the chunk held by
+// node e.
+func (d *Deque) newHandle(e *list.Element, i int) Handle {
+	r := &_Handle{deque: d}
+	setHandle(e, i, r)
+	d.handles++
+	return Handle{r}
+}
+
+// setHandle stores handle r for the item at index i in the chunk held by node
+// e.
+func setHandle(e *list.Element, i int, r *_Handle) {
+	b := e.Value.(*_Block)
+	if b.handles == nil {
+		b.handles = new([chunkSize]*_Handle)
+	}
+	b.handles[i] = r
+	r.node, r.i = e, i
+}
+
+// moveHandle moves the handle, if any, of the item at index i in the chunk held
+// by node e along with the item to index j in the chunk held by node w.
+func (d *Deque) moveHandle(e *list.Element, i int, w *list.Element, j int) {
+	b := e.Value.(*_Block)
+	if b.handles == nil || b.handles[i] == nil || (e == w && i == j) {
+		return
+	}
+	r := b.handles[i]
+	b.handles[i] = nil
+	setHandle(w, j, r)
+}
+
+// unhandle invalidates the handle, if any, of the item at index i in the chunk
+// held by node e.
+func (d *Deque) unhandle(e *list.Element, i int) {
+	b := e.Value.(*_Block)
+	if b.handles == nil || b.handles[i] == nil {
+		return
+	}
+	b.handles[i].deque = nil
+	b.handles[i].node = nil
+	b.handles[i] = nil
+	d.handles--
+}
+
+// unhandleAll invalidates the handles of the items in the chunk held by node e.
+func (d *Deque) unhandleAll(e *list.Element) {
+	if e.Value.(*_Block).handles == nil {
+		return
+	}
+	for i := 0; i < chunkSize; i++ {
+		d.unhandle(e, i)
+	}
+	e.Value.(*_Block).handles = nil
+}
+
+// trimFront drops the tombstones at the front of a non-empty deque, so that
+// the front item is a live one.
+func (d *Deque) trimFront() {
+	for isDead(d.fC[d.fI]) {
+		e := d.chunks.Front()
+		if d.shared == 0 || !isShared(e) {
+			d.fC[d.fI] = nil
+		}
+		e.Value.(*_Block).dead--
+		d.dead--
+		d.fI++
+		if d.fI == chunkSize { // 'front' chunk empty?
+			d.release(e)
+			d.chunks.Remove(e)
+			d.fI = 0
+			d.fC = d.chunks.Front().Value.(*_Block).chunk
+			if d.obs != nil {
+				d.obs.Shrank(1, d.chunks.Len())
+			}
+		}
+	}
+}
+
+// trimBack drops the tombstones at the back of a non-empty deque, so that the
+// back item is a live one.
+func (d *Deque) trimBack() {
+	for isDead(d.bC[d.bI]) {
+		e := d.chunks.Back()
+		if d.shared == 0 || !isShared(e) {
+			d.bC[d.bI] = nil
+		}
+		e.Value.(*_Block).dead--
+		d.dead--
+		d.bI--
+		if d.bI == -1 { // 'back' chunk empty?
+			d.release(e)
+			d.chunks.Remove(e)
+			d.bI = chunkSize - 1
+			d.bC = d.chunks.Back().Value.(*_Block).chunk
+			if d.obs != nil {
+				d.obs.Shrank(1, d.chunks.Len())
+			}
+		}
+	}
+}
+
+// live returns the number of live items in the chunk held by node e.
+func (d *Deque) live(e *list.Element) int {
+	return len(d.span(e)) - e.Value.(*_Block).dead
+}
+
+// compact merges the chunk held by node e with its previous or next neighbor,
+// if their live items fit in a single chunk.
+func (d *Deque) compact(e *list.Element) {
+	if p := e.Prev(); p != nil && d.live(p)+d.live(e) <= chunkSize {
+		d.merge(p, e)
+	} else if n := e.Next(); n != nil && d.live(e)+d.live(n) <= chunkSize {
+		d.merge(e, n)
+	}
+}
+
+// merge moves the live items of the chunks held by the adjacent nodes x and y
+// into a fresh chunk held by x, and removes y. The items are aligned to the
+// open end of the chunk at the front or back of the deque; a chunk inside the
+// deque is padded with tombstones.
+func (d *Deque) merge(x, y *list.Element) {
+	n := d.live(x) + d.live(y)
+	front, back := x == d.chunks.Front(), y == d.chunks.Back()
+	bx, by := x.Value.(*_Block), y.Value.(*_Block)
+	b := &_Block{chunk: make(_Chunk, chunkSize)}
+
+	j := 0
+	if front {
+		j = chunkSize - n
+	}
+	start := j
+	for _, e := range [2]*list.Element{x, y} {
+		lo := 0 // chunk index of the span
+		if e == d.chunks.Front() {
+			lo = d.fI
+		}
+		ob := e.Value.(*_Block)
+		for i, item := range d.span(e) {
+			if isDead(item) {
+				continue
+			}
+			b.chunk[j] = item
+			if ob.handles != nil && ob.handles[lo+i] != nil {
+				if b.handles == nil {
+					b.handles = new([chunkSize]*_Handle)
+				}
+				b.handles[j] = ob.handles[lo+i]
+				b.handles[j].node, b.handles[j].i = x, j
+			}
+			j++
+		}
+	}
+	if !front && !back {
+		for ; j < chunkSize; j++ {
+			b.chunk[j] = _Tombstone{}
+		}
+		b.dead = chunkSize - n
+	}
+	d.dead += b.dead - bx.dead - by.dead
+
+	d.release(x)
+	d.release(y)
+	x.Value = b
+	d.chunks.Remove(y)
+	if front {
+		d.fC, d.fI = b.chunk, start
+	}
+	if back {
+		d.bC, d.bI = b.chunk, start+n-1
+	}
+	if d.obs != nil {
+		d.obs.Shrank(1, d.chunks.Len())
+	}
+}
+
+// walk returns the chunk node and item index that lie n live items away from
+// the live item at index i in the chunk held by node, stepping over tombstones
+// and skipping whole chunks at once.
+func (d *Deque) walk(node *list.Element, i, n int) (*list.Element, int) {
+	if d.dead == 0 {
+		return locate(node, i, n)
+	}
+	chunk := node.Value.(*_Block).chunk
+	for n > 0 {
+		i++
+		if i == chunkSize { // next chunk?
+			node, i = node.Next(), 0
+			for n > d.live(node) {
+				n -= d.live(node)
+				node = node.Next()
+			}
+			chunk = node.Value.(*_Block).chunk
+		}
+		if !isDead(chunk[i]) {
+			n--
+		}
+	}
+	for n < 0 {
+		i--
+		if i < 0 { // previous chunk?
+			node, i = node.Prev(), chunkSize-1
+			for -n > d.live(node) {
+				n += d.live(node)
+				node = node.Prev()
+			}
+			chunk = node.Value.(*_Block).chunk
+		}
+		if !isDead(chunk[i]) {
+			n++
+		}
+	}
+	return node, i
+}
+
+// skipDead moves the iterator over the tombstones in direction dir, +1 towards
+// the back or -1 towards the front, onto the next live item.
+func (it *Iterator) skipDead(dir int) {
+	for isDead(it.chunk[it.i]) {
+		it.i += dir
+		if it.i >= chunkSize { // next chunk?
+			it.node = it.node.Next()
+			it.chunk = it.node.Value.(*_Block).chunk
+			it.i = 0
+		} else if it.i < 0 { // previous chunk?
+			it.node = it.node.Prev()
+			it.chunk = it.node.Value.(*_Block).chunk
+			it.i = chunkSize - 1
+		}
+	}
+}
+
+// runs calls yield with the live part of the chunk held by node e, split into
+// the runs of items between tombstones, and reports whether yield asked for
+// more. The runs are yielded from back to front if backward is set.
+func (d *Deque) runs(e *list.Element, backward bool, yield func([]interface{}) bool) bool {
+	span := d.span(e)
+	if e.Value.(*_Block).dead == 0 {
+		return yield(span)
+	}
+	if backward {
+		for hi := len(span); hi > 0; {
+			lo := hi
+			for lo > 0 && !isDead(span[lo-1]) {
+				lo--
+			}
+			if lo < hi && !yield(span[lo:hi:hi]) {
+				return false
+			}
+			for lo > 0 && isDead(span[lo-1]) {
+				lo--
+			}
+			hi = lo
+		}
+		return true
+	}
+	for lo := 0; lo < len(span); {
+		hi := lo
+		for hi < len(span) && !isDead(span[hi]) {
+			hi++
+		}
+		if lo < hi && !yield(span[lo:hi:hi]) {
+			return false
+		}
+		for hi < len(span) && isDead(span[hi]) {
+			hi++
+		}
+		lo = hi
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+// observer.go, jpad 2026
+
+package deque
+
+//// Observer //////////////////////////////////////////////////////////////////
+
+// Observer is notified of the changes of a deque it is attached to with
+// SetObserver. Each method is called right after the change, with the number
+// of affected items or chunks and the resulting size or chunk count. Bulk
+// operations like DiscardFront, DeleteFunc and Clear report a single event
+// for all of their items.
+//
+// The methods are called synchronously from the deque operations, so they
+// should be cheap, and they must not modify the deque.
+type Observer interface {
+	Pushed(n, size int)   // n items were added
+	Popped(n, size int)   // n items were removed
+	Grew(n, chunks int)   // n chunks were allocated
+	Shrank(n, chunks int) // n chunks were released
+}
+
+// SetObserver attaches an observer to the deque, replacing the previous one;
+// nil detaches it. The observer only learns of the changes made after it was
+// attached. Clones and derived deques, see Clone and Map, are not observed.
+func (d *Deque) SetObserver(o Observer) {
+	d.obs = o
+}
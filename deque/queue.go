@@ -0,0 +1,52 @@
+// queue.go, jpad 2026
+
+package deque
+
+//// Queue /////////////////////////////////////////////////////////////////////
+
+// Queue is a first in, first out queue of items of type T backed by a deque.
+type Queue[T any] struct {
+	deque *Deque
+}
+
+// NewQueue returns a pointer to an empty queue.
+func NewQueue[T any]() *Queue[T] {
+	return &Queue[T]{deque: New()}
+}
+
+// Enqueue adds an item to the back of the queue.
+func (q *Queue[T]) Enqueue(item T) {
+	q.deque.PushBack(item)
+}
+
+// Dequeue removes and returns the item from the front of the queue. The
+// boolean is false when the queue is empty.
+func (q *Queue[T]) Dequeue() (T, bool) {
+	if q.deque.Size() == 0 {
+		var zero T
+		return zero, false
+	}
+	item, _ := q.deque.PopFront().(T) // a nil item yields the zero value
+	return item, true
+}
+
+// Peek returns the item at the front of the queue. The boolean is false when
+// the queue is empty.
+func (q *Queue[T]) Peek() (T, bool) {
+	if q.deque.Size() == 0 {
+		var zero T
+		return zero, false
+	}
+	item, _ := q.deque.FrontItem().(T) // a nil item yields the zero value
+	return item, true
+}
+
+// Size returns the number of items in the queue.
+func (q *Queue[T]) Size() int {
+	return q.deque.Size()
+}
+
+// Clear removes all items from the queue.
+func (q *Queue[T]) Clear() {
+	q.deque.Clear()
+}
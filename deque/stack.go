@@ -0,0 +1,52 @@
+// stack.go, jpad 2026
+
+package deque
+
+//// Stack /////////////////////////////////////////////////////////////////////
+
+// Stack is a last in, first out stack of items of type T backed by a deque.
+type Stack[T any] struct {
+	deque *Deque
+}
+
+// NewStack returns a pointer to an empty stack.
+func NewStack[T any]() *Stack[T] {
+	return &Stack[T]{deque: New()}
+}
+
+// Push adds an item to the top of the stack.
+func (s *Stack[T]) Push(item T) {
+	s.deque.PushBack(item)
+}
+
+// Pop removes and returns the item from the top of the stack. The boolean is
+// false when the stack is empty.
+func (s *Stack[T]) Pop() (T, bool) {
+	if s.deque.Size() == 0 {
+		var zero T
+		return zero, false
+	}
+	item, _ := s.deque.PopBack().(T) // a nil item yields the zero value
+	return item, true
+}
+
+// Peek returns the item at the top of the stack. The boolean is false when the
+// stack is empty.
+func (s *Stack[T]) Peek() (T, bool) {
+	if s.deque.Size() == 0 {
+		var zero T
+		return zero, false
+	}
+	item, _ := s.deque.BackItem().(T) // a nil item yields the zero value
+	return item, true
+}
+
+// Size returns the number of items on the stack.
+func (s *Stack[T]) Size() int {
+	return s.deque.Size()
+}
+
+// Clear removes all items from the stack.
+func (s *Stack[T]) Clear() {
+	s.deque.Clear()
+}
@@ -9,6 +9,8 @@ package deque_int
 
 import (
 	"container/list"
+	"errors"
+	"iter"
 )
 
 const (
@@ -16,6 +18,10 @@ const (
 	chunkCenter = chunkSize / 2
 )
 
+// ErrConcurrentModification is the panic value raised when an iterator is
+// used after the deque it iterates over has been modified.
+var ErrConcurrentModification = errors.New("deque: concurrent modification during iteration")
+
 //// Deque /////////////////////////////////////////////////////////////////////
 
 // Deque is a double ended queue that can handle items of any type.
@@ -26,16 +32,15 @@ type Deque struct {
 	fI     int    // front item index
 	bI     int    // back item index
 	size   int
+	mods   int // modification counter, see Iterator
+
+	compressed bool // pack interior chunks, see NewCompressed
 }
 
 // New returns a pointer to an empty deque.
 func New() *Deque {
 	deque := Deque{}
-	deque.reset()
-	chunk := make(_Chunk, chunkSize)
-	deque.fC = chunk
-	deque.bC = chunk
-	deque.chunks.PushBack(chunk)
+	deque.init()
 	return &deque
 }
 
@@ -46,10 +51,14 @@ func (d *Deque) PushFront(item int) {
 		d.fC = make(_Chunk, chunkSize)
 		d.chunks.PushFront(d.fC)
 		d.fI = chunkSize
+		if d.compressed {
+			d.packFront()
+		}
 	}
 	d.fI--
 	d.fC[d.fI] = item
 	d.size++
+	d.mods++
 }
 
 // PushBack adds an item to the back of the deque.
@@ -59,10 +68,14 @@ func (d *Deque) PushBack(item int) {
 		d.bC = make(_Chunk, chunkSize)
 		d.chunks.PushBack(d.bC)
 		d.bI = -1
+		if d.compressed {
+			d.packBack()
+		}
 	}
 	d.bI++
 	d.bC[d.bI] = item
 	d.size++
+	d.mods++
 }
 
 // PopFront removes and returns the item from the front of the deque.
@@ -74,6 +87,7 @@ func (d *Deque) PopFront() int {
 	item := d.fC[d.fI]
 	d.fI++
 	d.size--
+	d.mods++
 
 	if d.fI == chunkSize { // 'front' chunk empty?
 		if d.size == 0 { // deque is empty, reset it
@@ -81,6 +95,9 @@ func (d *Deque) PopFront() int {
 		} else {
 			d.chunks.Remove(d.chunks.Front())
 			d.fI = 0
+			if d.compressed {
+				d.unpack(d.chunks.Front())
+			}
 			d.fC = d.chunks.Front().Value.(_Chunk)
 		}
 	}
@@ -97,6 +114,7 @@ func (d *Deque) PopBack() int {
 	item := d.bC[d.bI]
 	d.bI--
 	d.size--
+	d.mods++
 
 	if d.bI == -1 { // 'back' chunk empty?
 		if d.size == 0 { // deque is empty, reset it
@@ -104,6 +122,9 @@ func (d *Deque) PopBack() int {
 		} else {
 			d.chunks.Remove(d.chunks.Back())
 			d.bI = chunkSize - 1
+			if d.compressed {
+				d.unpack(d.chunks.Back())
+			}
 			d.bC = d.chunks.Back().Value.(_Chunk)
 		}
 	}
@@ -145,6 +166,7 @@ func (d *Deque) Front() *Iterator {
 		chunk: fNode.Value.(_Chunk),
 		i:     d.fI,
 		pos:   0,
+		mods:  d.mods,
 	}
 }
 
@@ -162,6 +184,51 @@ func (d *Deque) Back() *Iterator {
 		chunk: bNode.Value.(_Chunk),
 		i:     d.bI,
 		pos:   d.size - 1,
+		mods:  d.mods,
+	}
+}
+
+// Chunks returns an iterator over the live items of the deque, one contiguous
+// chunk at a time, from front to back. Each yielded slice aliases the deque's
+// storage and is only valid until the deque is modified; modifying the deque
+// before the iteration is done panics with ErrConcurrentModification. For a
+// compressed deque, the items of a packed frame are yielded at once, decoded
+// into a buffer that is only valid until the next slice is yielded.
+func (d *Deque) Chunks() iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		if d.size <= 0 {
+			return
+		}
+		mods := d.mods
+		var buf []int
+		for e := d.chunks.Front(); e != nil; e = e.Next() {
+			if !yield(d.span(e, &buf)) {
+				return
+			}
+			if checkMods && d.mods != mods {
+				panic(ErrConcurrentModification)
+			}
+		}
+	}
+}
+
+// ChunksBackward is like Chunks but yields the chunks from back to front. The
+// items within each slice remain in front to back order.
+func (d *Deque) ChunksBackward() iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		if d.size <= 0 {
+			return
+		}
+		mods := d.mods
+		var buf []int
+		for e := d.chunks.Back(); e != nil; e = e.Prev() {
+			if !yield(d.span(e, &buf)) {
+				return
+			}
+			if checkMods && d.mods != mods {
+				panic(ErrConcurrentModification)
+			}
+		}
 	}
 }
 
@@ -172,7 +239,18 @@ func (d *Deque) Size() int {
 
 // Clear removes all items from the deque.
 func (d *Deque) Clear() {
-	*d = *New()
+	d.init()
+	d.mods++
+}
+
+func (d *Deque) init() {
+	d.reset()
+	chunk := make(_Chunk, chunkSize)
+	d.fC = chunk
+	d.bC = chunk
+	d.chunks.Init()
+	d.chunks.PushBack(chunk)
+	d.size = 0
 }
 
 func (d *Deque) reset() {
@@ -180,9 +258,26 @@ func (d *Deque) reset() {
 	d.bI = chunkCenter
 }
 
+// span returns the live part of the chunk held by node e, decoding a packed
+// frame into buf.
+func (d *Deque) span(e *list.Element, buf *[]int) []int {
+	chunk := load(e, buf)
+	lo, hi := 0, len(chunk)
+	if e == d.chunks.Front() {
+		lo = d.fI
+	}
+	if e == d.chunks.Back() {
+		hi = d.bI + 1
+	}
+	return chunk[lo:hi:hi]
+}
+
 //// Iterator //////////////////////////////////////////////////////////////////
 
 // Iterator points to a deque item and can be used to iterate through the deque.
+// An iterator is invalidated by any modification of its deque; calling Next or
+// Prev on an invalidated iterator panics with ErrConcurrentModification. The
+// check can be compiled out with the deque_nocheck build tag.
 type Iterator struct {
 	Value int
 
@@ -191,19 +286,24 @@ type Iterator struct {
 	chunk _Chunk        // current chunk (shortcut)
 	i     int           // current item index
 	pos   int           // iteration position
+	mods  int           // deque modification counter at creation
+	buf   []int         // decoded packed frame, see NewCompressed
 }
 
 // Next returns an iterator that points to the next deque element, or nil if
 // there is no next element.
 func (it *Iterator) Next() *Iterator {
+	if checkMods && it.mods != it.deque.mods {
+		panic(ErrConcurrentModification)
+	}
 	it.pos++
 	if it.pos >= it.deque.size { // no more items
 		return nil
 	}
 	it.i++
-	if it.i >= chunkSize { // next chunk?
+	if it.i >= len(it.chunk) { // next chunk?
 		it.node = it.node.Next()
-		it.chunk = it.node.Value.(_Chunk)
+		it.chunk = load(it.node, &it.buf)
 		it.i = 0
 	}
 	it.Value = it.chunk[it.i]
@@ -213,6 +313,9 @@ func (it *Iterator) Next() *Iterator {
 // Prev returns an iterator that points to the previous deque element, or nil
 // if there is no previous element.
 func (it *Iterator) Prev() *Iterator {
+	if checkMods && it.mods != it.deque.mods {
+		panic(ErrConcurrentModification)
+	}
 	it.pos--
 	if it.pos < 0 { // no more items
 		return nil
@@ -220,8 +323,8 @@ func (it *Iterator) Prev() *Iterator {
 	it.i--
 	if it.i < 0 { // previous chunk?
 		it.node = it.node.Prev()
-		it.chunk = it.node.Value.(_Chunk)
-		it.i = chunkSize - 1
+		it.chunk = load(it.node, &it.buf)
+		it.i = len(it.chunk) - 1
 	}
 	it.Value = it.chunk[it.i]
 	return it
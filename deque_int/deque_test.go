@@ -4,7 +4,9 @@ package deque_int_test
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"runtime"
 	"testing"
 
 	"github.com/notnot/container/deque_int"
@@ -168,6 +170,27 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestClear_reuse(t *testing.T) {
+	const N = 100
+	deque := deque_int.New()
+
+	for i := 0; i < N; i++ {
+		deque.PushFront(i)
+	}
+	deque.Clear()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+	for i := 0; i < N; i++ {
+		if item := deque.PopFront(); item != i {
+			t.Errorf("got: %v, want: %d", item, i)
+		}
+	}
+	if deque.Size() != 0 {
+		t.Errorf("got: %d, want: 0", deque.Size())
+	}
+}
+
 func TestIterate(t *testing.T) {
 	const N = 1000
 	deque := deque_int.New()
@@ -194,6 +217,204 @@ func TestIterate(t *testing.T) {
 	}
 }
 
+func TestChunks(t *testing.T) {
+	const N = 1000
+	deque := deque_int.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+	for i := 1; i <= N/2; i++ {
+		deque.PushFront(-i)
+	}
+
+	// walk the chunks from front to back
+	want := -N / 2
+	for chunk := range deque.Chunks() {
+		for _, item := range chunk {
+			if item != want {
+				t.Errorf("got: %v, want: %d", item, want)
+			}
+			want++
+		}
+	}
+	if want != N {
+		t.Errorf("got: %d items, want: %d", want+N/2, N+N/2)
+	}
+
+	// walk the chunks from back to front
+	want = N - 1
+	for chunk := range deque.ChunksBackward() {
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != want {
+				t.Errorf("got: %v, want: %d", chunk[i], want)
+			}
+			want--
+		}
+	}
+	if want != -N/2-1 {
+		t.Errorf("got: %d items, want: %d", N-1-want, N+N/2)
+	}
+}
+
+func TestChunks_empty(t *testing.T) {
+	deque := deque_int.New()
+	for chunk := range deque.Chunks() {
+		t.Errorf("got: %v, want: no chunks", chunk)
+	}
+
+	deque.PushBack(1)
+	deque.PopFront()
+	for chunk := range deque.ChunksBackward() {
+		t.Errorf("got: %v, want: no chunks", chunk)
+	}
+}
+
+// TestCompressed checks a compressed deque against a slice, with runs of items
+// that suit each codec.
+func TestCompressed(t *testing.T) {
+	deque := deque_int.NewCompressed()
+	model := []int{}
+	value := 0
+
+	check := func(op string) {
+		t.Helper()
+		if deque.Size() != len(model) {
+			t.Fatalf("%s: got: %d, want: %d", op, deque.Size(), len(model))
+		}
+		i := 0
+		for it := deque.Front(); it != nil; it = it.Next() {
+			if it.Value != model[i] {
+				t.Fatalf("%s: front[%d]: got: %d, want: %d", op, i, it.Value, model[i])
+			}
+			i++
+		}
+		i = len(model)
+		for chunk := range deque.ChunksBackward() {
+			i -= len(chunk)
+			for j, item := range chunk {
+				if item != model[i+j] {
+					t.Fatalf("%s: chunks[%d]: got: %d, want: %d", op, i+j, item, model[i+j])
+				}
+			}
+		}
+	}
+
+	for n := 0; n < 100; n++ {
+		var next func() int
+		switch rand.Intn(5) {
+		case 0: // consecutive IDs
+			next = func() int { value++; return value }
+		case 1: // timestamps with jitter
+			next = func() int { value += 1e6 + rand.Intn(1000); return value }
+		case 2: // small random values
+			next = func() int { return rand.Intn(100) }
+		case 3: // full range
+			next = func() int { return int(rand.Uint64()) }
+		case 4: // extremes
+			next = func() int { return []int{math.MinInt, math.MaxInt, 0}[rand.Intn(3)] }
+		}
+		k := rand.Intn(1000)
+		switch rand.Intn(4) {
+		case 0, 1:
+			for i := 0; i < k; i++ {
+				v := next()
+				deque.PushBack(v)
+				model = append(model, v)
+			}
+			check("PushBack")
+		case 2:
+			for i := 0; i < k; i++ {
+				v := next()
+				deque.PushFront(v)
+				model = append([]int{v}, model...)
+			}
+			check("PushFront")
+		case 3:
+			for i := 0; i < k && len(model) > 0; i++ {
+				if rand.Intn(2) == 0 {
+					if v := deque.PopFront(); v != model[0] {
+						t.Fatalf("got: %d, want: %d", v, model[0])
+					}
+					model = model[1:]
+				} else {
+					if v := deque.PopBack(); v != model[len(model)-1] {
+						t.Fatalf("got: %d, want: %d", v, model[len(model)-1])
+					}
+					model = model[:len(model)-1]
+				}
+			}
+			check("Pop")
+		}
+	}
+}
+
+// TestCompressed_guards checks that the chunk next to the front chunk and the
+// chunk next to the back chunk are never packed, whichever end is pushed to.
+func TestCompressed_guards(t *testing.T) {
+	const chunkSize = 32
+	for _, front := range []bool{false, true} {
+		deque := deque_int.NewCompressed()
+		packed := false
+		for i := 0; i < 30*chunkSize; i++ {
+			if front {
+				deque.PushFront(i)
+			} else {
+				deque.PushBack(i)
+			}
+			sizes := []int{}
+			for chunk := range deque.Chunks() {
+				sizes = append(sizes, len(chunk))
+			}
+			n := len(sizes)
+			if n >= 3 && (sizes[1] > chunkSize || sizes[n-2] > chunkSize) {
+				t.Fatalf("front: %v, %d items: got: %v, want: guard chunks unpacked", front, i+1, sizes)
+			}
+			for _, size := range sizes {
+				packed = packed || size > chunkSize
+			}
+		}
+		if !packed {
+			t.Errorf("front: %v: got: no packed frames, want: some", front)
+		}
+	}
+}
+
+func TestCompressed_memory(t *testing.T) {
+	const N = 1 << 20
+	heap := func(fill func(deque *deque_int.Deque)) uint64 {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		deques := []*deque_int.Deque{deque_int.New(), deque_int.NewCompressed()}
+		fill(deques[0])
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		plain := after.HeapAlloc - before.HeapAlloc
+		fill(deques[1])
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		runtime.KeepAlive(deques)
+		return plain / (before.HeapAlloc - after.HeapAlloc)
+	}
+
+	ids := heap(func(deque *deque_int.Deque) {
+		for i := 0; i < N; i++ {
+			deque.PushBack(1e9 + i)
+		}
+	})
+	stamps := heap(func(deque *deque_int.Deque) {
+		stamp := int(1420070400 * 1e9)
+		for i := 0; i < N; i++ {
+			stamp += 1e6 + rand.Intn(1000)
+			deque.PushBack(stamp)
+		}
+	})
+	t.Logf("saving: ids %dx, timestamps %dx", ids, stamps)
+	if ids < 10 || stamps < 5 {
+		t.Errorf("got: %dx, %dx, want: at least 10x, 5x", ids, stamps)
+	}
+}
+
 //// benchmarks ////////////////////////////////////////////////////////////////
 
 func BenchmarkPushPopFront_10(b *testing.B) {
@@ -336,6 +557,64 @@ func BenchmarkIterate_backward(b *testing.B) {
 	}
 }
 
+func BenchmarkIterate_chunks(b *testing.B) {
+	const N = 1024
+	deque := deque_int.New()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for chunk := range deque.Chunks() {
+			for _, item := range chunk {
+				_ = item
+			}
+		}
+	}
+}
+
+func BenchmarkIterate_compressed(b *testing.B) {
+	const N = 1024
+	deque := deque_int.NewCompressed()
+	for i := 0; i < N; i++ {
+		deque.PushBack(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for it := deque.Front(); it != nil; it = it.Next() {
+			_ = it.Value
+		}
+	}
+}
+
+// BenchmarkPushPop_compressedBoundary pops and pushes around the boundary of
+// the back chunk of a compressed deque, with a packed frame close by.
+func BenchmarkPushPop_compressedBoundary(b *testing.B) {
+	for _, bc := range []struct {
+		name  string
+		deque *deque_int.Deque
+	}{
+		{"plain", deque_int.New()},
+		{"compressed", deque_int.NewCompressed()},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			// fill the first chunk and 9 more, then start a new back chunk
+			for i := 0; i < 15+9*32+1; i++ {
+				bc.deque.PushBack(i)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				bc.deque.PopBack()
+				bc.deque.PushBack(i)
+			}
+		})
+	}
+}
+
 //// examples //////////////////////////////////////////////////////////////////
 
 func ExampleIterator() {
@@ -0,0 +1,8 @@
+// nocheck.go, jpad 2026
+
+//go:build deque_nocheck
+
+package deque_int
+
+// checkMods disables the detection of deque modifications during iteration.
+const checkMods = false
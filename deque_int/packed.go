@@ -0,0 +1,280 @@
+// packed.go, jpad 2026
+
+package deque_int
+
+import (
+	"container/list"
+	"encoding/binary"
+	"math/bits"
+)
+
+const packChunks = 8 // chunks per packed frame
+
+// Codecs of a packed frame.
+const (
+	packValues = iota // frame of reference, bit-packed values
+	packDeltas        // frame of reference, bit-packed deltas
+	packVarint        // varint deltas
+)
+
+//// _Packed ///////////////////////////////////////////////////////////////////
+
+// _Packed holds the items of packChunks full interior chunks in compressed
+// form, see NewCompressed. The codec that yields the least data is used.
+type _Packed struct {
+	n     int   // number of items
+	codec uint8 // packValues, packDeltas or packVarint
+	width uint8 // bits per value or delta, bit-packed codecs
+	first int   // first item, delta codecs
+	base  int   // frame of reference, bit-packed codecs
+	data  []byte
+}
+
+// pack returns the items in compressed form.
+func pack(items []int) *_Packed {
+	p := &_Packed{n: len(items), first: items[0]}
+
+	vBase, vWidth := frame(len(items), func(i int) int { return items[i] })
+	dBase, dWidth := frame(len(items)-1, func(i int) int { return items[i+1] - items[i] })
+	var varint []byte
+	for i := 1; i < len(items); i++ {
+		varint = binary.AppendVarint(varint, int64(items[i]-items[i-1]))
+	}
+
+	vSize := (len(items)*int(vWidth) + 7) / 8
+	dSize := ((len(items)-1)*int(dWidth) + 7) / 8
+	switch {
+	case len(varint) < vSize && len(varint) < dSize:
+		p.codec, p.data = packVarint, varint
+	case dSize < vSize:
+		p.codec, p.base, p.width = packDeltas, dBase, dWidth
+		w := bitWriter{data: make([]byte, 0, dSize)}
+		for i := 1; i < len(items); i++ {
+			w.put(uint64(items[i]-items[i-1]-dBase), uint(dWidth))
+		}
+		p.data = w.flush()
+	default:
+		p.codec, p.base, p.width = packValues, vBase, vWidth
+		w := bitWriter{data: make([]byte, 0, vSize)}
+		for _, v := range items {
+			w.put(uint64(v-vBase), uint(vWidth))
+		}
+		p.data = w.flush()
+	}
+	return p
+}
+
+// frame returns the frame of reference of n values, their minimum, and the
+// number of bits needed for the largest offset from it.
+func frame(n int, value func(i int) int) (int, uint8) {
+	if n == 0 {
+		return 0, 0
+	}
+	lo, hi := value(0), value(0)
+	for i := 1; i < n; i++ {
+		v := value(i)
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	return lo, uint8(bits.Len64(uint64(hi - lo)))
+}
+
+// unpack decodes the items into dst, which holds at least p.n items.
+func (p *_Packed) unpack(dst []int) {
+	switch p.codec {
+	case packValues:
+		r := bitReader{data: p.data}
+		for i := 0; i < p.n; i++ {
+			dst[i] = p.base + int(r.get(uint(p.width)))
+		}
+	case packDeltas:
+		r := bitReader{data: p.data}
+		dst[0] = p.first
+		for i := 1; i < p.n; i++ {
+			dst[i] = dst[i-1] + p.base + int(r.get(uint(p.width)))
+		}
+	case packVarint:
+		data := p.data
+		dst[0] = p.first
+		for i := 1; i < p.n; i++ {
+			delta, k := binary.Varint(data)
+			dst[i] = dst[i-1] + int(delta)
+			data = data[k:]
+		}
+	}
+}
+
+// bitWriter appends values of up to 64 bits to a byte slice, least
+// significant bit first.
+type bitWriter struct {
+	data []byte
+	acc  uint64 // pending bits
+	n    uint   // number of pending bits, less than 8 between puts
+}
+
+func (w *bitWriter) put(x uint64, width uint) {
+	if width > 32 {
+		w.put(x&(1<<32-1), 32)
+		w.put(x>>32, width-32)
+		return
+	}
+	w.acc |= (x & (1<<width - 1)) << w.n
+	w.n += width
+	for w.n >= 8 {
+		w.data = append(w.data, byte(w.acc))
+		w.acc >>= 8
+		w.n -= 8
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.n > 0 {
+		w.data = append(w.data, byte(w.acc))
+		w.acc, w.n = 0, 0
+	}
+	return w.data
+}
+
+// bitReader reads the values written by a bitWriter.
+type bitReader struct {
+	data []byte
+	acc  uint64
+	n    uint
+}
+
+func (r *bitReader) get(width uint) uint64 {
+	if width > 32 {
+		lo := r.get(32)
+		return lo | r.get(width-32)<<32
+	}
+	for r.n < width {
+		r.acc |= uint64(r.data[0]) << r.n
+		r.data = r.data[1:]
+		r.n += 8
+	}
+	x := r.acc & (1<<width - 1)
+	r.acc >>= width
+	r.n -= width
+	return x
+}
+
+//// compressed deque //////////////////////////////////////////////////////////
+
+// NewCompressed returns a pointer to an empty deque that stores its interior
+// chunks in compressed form. Once packChunks full chunks have accumulated
+// inside the deque, away from the front and back chunks that are being
+// mutated and the full chunk next to each of them, they are packed into a
+// single frame, using frame of reference bit-packing of the items or of their
+// deltas, or varint deltas, whichever is smallest. A frame is unpacked again
+// when it becomes the front or back of the deque, and decoded on the fly when
+// an iterator or Chunks reaches it.
+//
+// This pays off for long runs of similar or steadily increasing integers, like
+// timestamps and IDs: a frame of consecutive IDs takes a few dozen bytes
+// instead of kilobytes.
+func NewCompressed() *Deque {
+	deque := New()
+	deque.compressed = true
+	return deque
+}
+
+// load returns the items of the chunk or packed frame held by node e,
+// decoding a frame into buf.
+func load(e *list.Element, buf *[]int) []int {
+	switch c := e.Value.(type) {
+	case _Chunk:
+		return c
+	case *_Packed:
+		if cap(*buf) < c.n {
+			*buf = make([]int, c.n)
+		}
+		*buf = (*buf)[:c.n]
+		c.unpack(*buf)
+		return *buf
+	}
+	return nil
+}
+
+// packBack packs the full chunks in front of the back chunk once there are
+// packChunks of them, besides the one right in front of the back chunk and the
+// one right behind the front chunk. Those stay unpacked, so that popping and
+// pushing around the chunk boundary at either end doesn't unpack and repack a
+// frame every time.
+func (d *Deque) packBack() {
+	guard, far := d.chunks.Back().Prev(), d.chunks.Front().Next()
+	if guard == nil || guard == d.chunks.Front() || guard == far {
+		return
+	}
+	e, k := guard.Prev(), 0
+	for ; k < packChunks && e != far; k++ {
+		if _, ok := e.Value.(_Chunk); !ok {
+			return
+		}
+		e = e.Prev()
+	}
+	if k == packChunks {
+		d.packRun(guard.Prev(), -1)
+	}
+}
+
+// packFront packs the full chunks behind the front chunk once there are
+// packChunks of them, besides the one right behind the front chunk and the one
+// right in front of the back chunk, see packBack.
+func (d *Deque) packFront() {
+	guard, far := d.chunks.Front().Next(), d.chunks.Back().Prev()
+	if guard == nil || guard == d.chunks.Back() || guard == far {
+		return
+	}
+	e, k := guard.Next(), 0
+	for ; k < packChunks && e != far; k++ {
+		if _, ok := e.Value.(_Chunk); !ok {
+			return
+		}
+		e = e.Next()
+	}
+	if k == packChunks {
+		d.packRun(guard.Next(), +1)
+	}
+}
+
+// packRun replaces the packChunks chunks starting at node e, towards the back
+// if dir is +1 or towards the front if dir is -1, with a single packed frame.
+func (d *Deque) packRun(e *list.Element, dir int) {
+	items := make([]int, 0, packChunks*chunkSize)
+	nodes := [packChunks]*list.Element{}
+	for k := range nodes {
+		nodes[k] = e
+		if dir > 0 {
+			e = e.Next()
+		} else {
+			e = e.Prev()
+		}
+	}
+	if dir < 0 { // front to back order
+		for k, j := 0, packChunks-1; k < j; k, j = k+1, j-1 {
+			nodes[k], nodes[j] = nodes[j], nodes[k]
+		}
+	}
+	for _, node := range nodes {
+		items = append(items, node.Value.(_Chunk)...)
+	}
+	nodes[0].Value = pack(items)
+	for _, node := range nodes[1:] {
+		d.chunks.Remove(node)
+	}
+}
+
+// unpack replaces the packed frame held by node e, if any, with its chunks.
+func (d *Deque) unpack(e *list.Element) {
+	p, ok := e.Value.(*_Packed)
+	if !ok {
+		return
+	}
+	items := make([]int, p.n)
+	p.unpack(items)
+	for lo := 0; lo < p.n; lo += chunkSize {
+		chunk := make(_Chunk, chunkSize)
+		copy(chunk, items[lo:])
+		d.chunks.InsertBefore(chunk, e)
+	}
+	d.chunks.Remove(e)
+}
@@ -0,0 +1,284 @@
+// dequetest.go, jpad 2026
+
+/*
+Package dequetest implements a conformance test suite for deque
+implementations. It holds the behaviour expected from deque.Deque, so adapters
+around it (logging, metrics, synchronization) can be verified against the
+same contract:
+
+	func TestConformance(t *testing.T) {
+		dequetest.Run(t, func() dequetest.Deque { return NewLoggingDeque() })
+	}
+*/
+package dequetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/notnot/container/deque"
+)
+
+// Deque is the interface of the deque implementations under test.
+type Deque interface {
+	PushFront(item interface{})
+	PushBack(item interface{})
+	PopFront() interface{}
+	PopBack() interface{}
+	FrontItem() interface{}
+	BackItem() interface{}
+	Front() *deque.Iterator
+	Back() *deque.Iterator
+	Size() int
+	Clear()
+}
+
+// Run runs the conformance tests as subtests of t. The factory is called once
+// per subtest and must return a new, empty deque.
+func Run(t *testing.T, factory func() Deque) {
+	t.Run("Empty", func(t *testing.T) { testEmpty(t, factory()) })
+	t.Run("PushPeek", func(t *testing.T) { testPushPeek(t, factory()) })
+	t.Run("PushPop", func(t *testing.T) { testPushPop(t, factory()) })
+	t.Run("PushPopRandom", func(t *testing.T) { testPushPopRandom(t, factory()) })
+	t.Run("Size", func(t *testing.T) { testSize(t, factory()) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, factory()) })
+	t.Run("Iterate", func(t *testing.T) { testIterate(t, factory()) })
+	t.Run("Model", func(t *testing.T) { testModel(t, factory()) })
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func testEmpty(t *testing.T, d Deque) {
+	for i := 0; i < 3; i++ {
+		if front := d.PopFront(); front != nil {
+			t.Errorf("PopFront: got: %v, want: <nil>", front)
+		}
+		if back := d.PopBack(); back != nil {
+			t.Errorf("PopBack: got: %v, want: <nil>", back)
+		}
+	}
+	if d.Size() != 0 {
+		t.Errorf("Size: got: %d, want: 0", d.Size())
+	}
+	if front := d.FrontItem(); front != nil {
+		t.Errorf("FrontItem: got: %v, want: <nil>", front)
+	}
+	if back := d.BackItem(); back != nil {
+		t.Errorf("BackItem: got: %v, want: <nil>", back)
+	}
+	if front := d.Front(); front != nil {
+		t.Errorf("Front: got: %v, want: <nil>", front.Value)
+	}
+	if back := d.Back(); back != nil {
+		t.Errorf("Back: got: %v, want: <nil>", back.Value)
+	}
+}
+
+func testPushPeek(t *testing.T, d Deque) {
+	d.PushFront("a")
+	if d.FrontItem() != "a" {
+		t.Errorf("got: %v, want: a", d.FrontItem())
+	}
+
+	d.PushBack("z")
+	if d.BackItem() != "z" {
+		t.Errorf("got: %v, want: z", d.BackItem())
+	}
+}
+
+func testPushPop(t *testing.T, d Deque) {
+	d.PushFront("a")
+	d.PushBack("z")
+
+	if front := d.PopFront(); front != "a" {
+		t.Errorf("got: %v, want: a", front)
+	}
+	if back := d.PopBack(); back != "z" {
+		t.Errorf("got: %v, want: z", back)
+	}
+}
+
+func testPushPopRandom(t *testing.T, d Deque) {
+	const N = 1000
+
+	// randomly push items to the front or to the back
+	for i := 0; i < N; i++ {
+		switch rand.Intn(2) {
+		case 0:
+			d.PushFront("f")
+		case 1:
+			d.PushBack("b")
+		}
+	}
+
+	// randomly pop items from the front or from the back
+	for i := 0; i < N; i++ {
+		switch rand.Intn(2) {
+		case 0:
+			if d.PopFront() == nil {
+				t.Errorf("deque empty!")
+			}
+		case 1:
+			if d.PopBack() == nil {
+				t.Errorf("deque empty!")
+			}
+		}
+	}
+}
+
+func testSize(t *testing.T, d Deque) {
+	const N = 100
+
+	for i := 0; i < N; i++ {
+		d.PushFront(i)
+	}
+	if d.Size() != N {
+		t.Errorf("got: %d, want: %d", d.Size(), N)
+	}
+	for d.Size() > 0 {
+		d.PopFront()
+	}
+
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+	}
+	if d.Size() != N {
+		t.Errorf("got: %d, want: %d", d.Size(), N)
+	}
+	for d.Size() > 0 {
+		d.PopBack()
+	}
+	if d.Size() != 0 {
+		t.Errorf("got: %d, want: 0", d.Size())
+	}
+}
+
+func testClear(t *testing.T, d Deque) {
+	const N = 100
+
+	for i := 0; i < N; i++ {
+		d.PushFront(i)
+	}
+	d.Clear()
+	if d.Size() != 0 {
+		t.Errorf("got: %d, want: 0", d.Size())
+	}
+
+	// the deque must remain usable
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+	}
+	for i := 0; i < N; i++ {
+		if item := d.PopFront(); item != i {
+			t.Errorf("got: %v, want: %d", item, i)
+		}
+	}
+}
+
+func testIterate(t *testing.T, d Deque) {
+	const N = 1000
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+	}
+
+	// iterate from front to back
+	i := 0
+	for e := d.Front(); e != nil; e = e.Next() {
+		if e.Value != i {
+			t.Errorf("got: %v, want: %d", e.Value, i)
+		}
+		i++
+	}
+	if i != N {
+		t.Errorf("got: %d items, want: %d", i, N)
+	}
+
+	// iterate from back to front
+	i = N - 1
+	for e := d.Back(); e != nil; e = e.Prev() {
+		if e.Value != i {
+			t.Errorf("got: %v, want: %d", e.Value, i)
+		}
+		i--
+	}
+	if i != -1 {
+		t.Errorf("got: %d items, want: %d", N-1-i, N)
+	}
+}
+
+// testModel applies random operations to the deque and to a slice holding
+// the same items, and checks that both agree after every operation.
+func testModel(t *testing.T, d Deque) {
+	const N = 10000
+	model := []interface{}{}
+
+	for i := 0; i < N; i++ {
+		switch op := rand.Intn(100); {
+		case op < 30:
+			d.PushFront(i)
+			model = append([]interface{}{i}, model...)
+		case op < 60:
+			d.PushBack(i)
+			model = append(model, i)
+		case op < 78:
+			var want interface{}
+			if len(model) > 0 {
+				want, model = model[0], model[1:]
+			}
+			if got := d.PopFront(); got != want {
+				t.Fatalf("%d: PopFront: got: %v, want: %v", i, got, want)
+			}
+		case op < 96:
+			var want interface{}
+			if len(model) > 0 {
+				want, model = model[len(model)-1], model[:len(model)-1]
+			}
+			if got := d.PopBack(); got != want {
+				t.Fatalf("%d: PopBack: got: %v, want: %v", i, got, want)
+			}
+		case op < 99:
+			checkItems(t, i, d, model)
+		default:
+			d.Clear()
+			model = model[:0]
+		}
+
+		if d.Size() != len(model) {
+			t.Fatalf("%d: Size: got: %d, want: %d", i, d.Size(), len(model))
+		}
+		if len(model) > 0 {
+			if d.FrontItem() != model[0] {
+				t.Fatalf("%d: FrontItem: got: %v, want: %v", i, d.FrontItem(), model[0])
+			}
+			if d.BackItem() != model[len(model)-1] {
+				t.Fatalf("%d: BackItem: got: %v, want: %v", i, d.BackItem(), model[len(model)-1])
+			}
+		}
+	}
+	checkItems(t, N, d, model)
+}
+
+// checkItems checks the deque items against the model in both directions.
+func checkItems(t *testing.T, step int, d Deque, model []interface{}) {
+	i := 0
+	for e := d.Front(); e != nil; e = e.Next() {
+		if i >= len(model) || e.Value != model[i] {
+			t.Fatalf("%d: Next: item %d differs from the model", step, i)
+		}
+		i++
+	}
+	if i != len(model) {
+		t.Fatalf("%d: Next: got: %d items, want: %d", step, i, len(model))
+	}
+
+	i = len(model) - 1
+	for e := d.Back(); e != nil; e = e.Prev() {
+		if i < 0 || e.Value != model[i] {
+			t.Fatalf("%d: Prev: item %d differs from the model", step, i)
+		}
+		i--
+	}
+	if i != -1 {
+		t.Fatalf("%d: Prev: got: %d items, want: %d", step, len(model)-1-i, len(model))
+	}
+}
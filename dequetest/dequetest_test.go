@@ -0,0 +1,16 @@
+// dequetest_test.go, jpad 2026
+
+package dequetest_test
+
+import (
+	"testing"
+
+	"github.com/notnot/container/deque"
+	"github.com/notnot/container/dequetest"
+)
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestDeque(t *testing.T) {
+	dequetest.Run(t, func() dequetest.Deque { return deque.New() })
+}
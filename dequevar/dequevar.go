@@ -0,0 +1,95 @@
+// dequevar.go, jpad 2026
+
+/*
+Package dequevar publishes the metrics of a deque through expvar, so queue
+depth can be watched in production without touching the call sites.
+
+A Metrics value is a deque.Observer and an expvar.Var at the same time. It
+reports, as a JSON object:
+
+	size          current number of items
+	high_water    largest size seen
+	chunks        current number of chunks
+	chunk_allocs  chunks allocated so far
+	pushes        items pushed so far
+	pops          items popped so far
+
+The pushes and pops counters measure throughput when sampled over time.
+*/
+package dequevar
+
+import (
+	"expvar"
+
+	"github.com/notnot/container/deque"
+)
+
+//// Metrics ///////////////////////////////////////////////////////////////////
+
+// Metrics collects the metrics of a single deque. It is updated by the
+// goroutine that owns the deque and can be read concurrently through expvar.
+type Metrics struct {
+	vars        expvar.Map
+	size        expvar.Int
+	highWater   expvar.Int
+	chunks      expvar.Int
+	chunkAllocs expvar.Int
+	pushes      expvar.Int
+	pops        expvar.Int
+}
+
+// New returns a pointer to a new, unpublished set of metrics. Attach it to a
+// deque with SetObserver and publish it with expvar.Publish.
+func New() *Metrics {
+	m := &Metrics{}
+	m.vars.Set("size", &m.size)
+	m.vars.Set("high_water", &m.highWater)
+	m.vars.Set("chunks", &m.chunks)
+	m.vars.Set("chunk_allocs", &m.chunkAllocs)
+	m.vars.Set("pushes", &m.pushes)
+	m.vars.Set("pops", &m.pops)
+	return m
+}
+
+// Publish attaches new metrics to deque d and publishes them under name. Like
+// expvar.Publish, it panics when name is already in use.
+func Publish(name string, d *deque.Deque) *Metrics {
+	m := New()
+	m.size.Set(int64(d.Size()))
+	m.highWater.Set(int64(d.Size()))
+	m.chunks.Set(int64(d.ChunkCount()))
+	d.SetObserver(m)
+	expvar.Publish(name, m)
+	return m
+}
+
+// String returns the metrics as a JSON object, see expvar.Var.
+func (m *Metrics) String() string {
+	return m.vars.String()
+}
+
+// Pushed implements deque.Observer.
+func (m *Metrics) Pushed(n, size int) {
+	m.pushes.Add(int64(n))
+	m.size.Set(int64(size))
+	if int64(size) > m.highWater.Value() {
+		m.highWater.Set(int64(size))
+	}
+}
+
+// Popped implements deque.Observer.
+func (m *Metrics) Popped(n, size int) {
+	m.pops.Add(int64(n))
+	m.size.Set(int64(size))
+}
+
+// Grew implements deque.Observer.
+func (m *Metrics) Grew(n, chunks int) {
+	m.chunkAllocs.Add(int64(n))
+	m.chunks.Set(int64(chunks))
+}
+
+// Shrank implements deque.Observer.
+func (m *Metrics) Shrank(n, chunks int) {
+	m.chunks.Set(int64(chunks))
+}
@@ -0,0 +1,75 @@
+// dequevar_test.go, jpad 2026
+
+package dequevar_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"strconv"
+	"testing"
+
+	"github.com/notnot/container/deque"
+	"github.com/notnot/container/dequevar"
+)
+
+// values decodes the JSON object published by a var.
+func values(t *testing.T, v expvar.Var) map[string]int {
+	values := map[string]int{}
+	if err := json.Unmarshal([]byte(v.String()), &values); err != nil {
+		t.Fatalf("got: %v, want: JSON object", err)
+	}
+	return values
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestMetrics(t *testing.T) {
+	d := deque.New()
+	m := dequevar.New()
+	d.SetObserver(m)
+
+	for i := 0; i < 100; i++ {
+		d.PushBack(i)
+	}
+	for i := 0; i < 60; i++ {
+		d.PopFront()
+	}
+	d.PushFront(-1)
+
+	got := values(t, m)
+	want := map[string]int{
+		"size":         41,
+		"high_water":   100,
+		"chunks":       2, // items 47..78 and 79..99, the front one reused
+		"chunk_allocs": 3, // the first chunk predates the observer
+		"pushes":       101,
+		"pops":         60,
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s: got: %d, want: %d", k, got[k], w)
+		}
+	}
+}
+
+// publishes numbers the expvar names of TestPublish, which can't be published
+// twice when the test is run again with -count.
+var publishes int
+
+func TestPublish(t *testing.T) {
+	publishes++
+	name := "dequevar_test_" + strconv.Itoa(publishes)
+	d := deque.New()
+	d.PushBack("a")
+	dequevar.Publish(name, d)
+	if got := values(t, expvar.Get(name)); got["chunks"] != d.ChunkCount() {
+		t.Errorf("got: %d, want: %d", got["chunks"], d.ChunkCount())
+	}
+	d.PushBack("b")
+	d.Clear()
+
+	got := values(t, expvar.Get(name))
+	if got["size"] != 0 || got["high_water"] != 2 || got["pops"] != 2 {
+		t.Errorf("got: %v, want: size 0, high_water 2, pops 2", got)
+	}
+}
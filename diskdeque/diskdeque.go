@@ -0,0 +1,438 @@
+// diskdeque.go, jpad 2026
+
+/*
+Package diskdeque implements a double ended queue that can grow larger than
+memory. Like deque.Deque it stores its items in chunks, but only the two chunks
+at the front and the two at the back are kept in memory. Full chunks in between
+are spilled to segment files in a directory, and loaded again when the front or
+the back of the deque reaches them. Keeping a second chunk at each end means
+that pushing and popping around a chunk boundary doesn't touch the disk. Items
+are converted to and from bytes by a pluggable Codec.
+
+The segment files outlive the process: opening the directory again restores
+the deque. Flush and Close write the in-memory chunks to segment files as well.
+After a crash, items that were only held in memory are lost, and items of a
+segment that was being consumed when the crash happened are delivered again.
+*/
+package diskdeque
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/notnot/container/deque"
+	"github.com/notnot/container/deque_int"
+)
+
+// DefaultChunkSize is the number of items per chunk when Options.ChunkSize is
+// not set.
+const DefaultChunkSize = 1024
+
+// ErrClosed is returned when a closed deque is used.
+var ErrClosed = errors.New("diskdeque: deque is closed")
+
+//// Options ///////////////////////////////////////////////////////////////////
+
+// SyncPolicy controls when segment files are fsynced.
+type SyncPolicy int
+
+const (
+	// SyncNever leaves flushing segment files to the operating system.
+	SyncNever SyncPolicy = iota
+	// SyncSegments fsyncs every segment file, and its directory, as it is
+	// written or removed.
+	SyncSegments
+)
+
+// Options configure a deque. The zero value selects the defaults.
+type Options struct {
+	ChunkSize int        // items per chunk and segment file, default DefaultChunkSize
+	Codec     Codec      // item codec, default BytesCodec
+	Sync      SyncPolicy // default SyncNever
+}
+
+//// Codec /////////////////////////////////////////////////////////////////////
+
+// Codec converts items to and from their stored form.
+type Codec interface {
+	Encode(item interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+// BytesCodec stores items of type []byte as they are.
+type BytesCodec struct{}
+
+// Encode returns item, which must be a []byte.
+func (BytesCodec) Encode(item interface{}) ([]byte, error) {
+	data, ok := item.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("diskdeque: BytesCodec: cannot encode %T", item)
+	}
+	return data, nil
+}
+
+// Decode returns data.
+func (BytesCodec) Decode(data []byte) (interface{}, error) {
+	return data, nil
+}
+
+// StringCodec stores items of type string.
+type StringCodec struct{}
+
+// Encode returns the bytes of item, which must be a string.
+func (StringCodec) Encode(item interface{}) ([]byte, error) {
+	s, ok := item.(string)
+	if !ok {
+		return nil, fmt.Errorf("diskdeque: StringCodec: cannot encode %T", item)
+	}
+	return []byte(s), nil
+}
+
+// Decode returns data as a string.
+func (StringCodec) Decode(data []byte) (interface{}, error) {
+	return string(data), nil
+}
+
+//// _Chunk ////////////////////////////////////////////////////////////////////
+
+// _Chunk is an in-memory chunk of the deque. The file of the segment a chunk
+// was loaded from is removed once the chunk is consumed or written out again.
+type _Chunk struct {
+	items  *deque.Deque
+	seq    int  // segment the chunk was loaded from
+	loaded bool // whether the chunk was loaded from segment seq
+}
+
+func newChunk() *_Chunk {
+	return &_Chunk{items: deque.New()}
+}
+
+//// Deque /////////////////////////////////////////////////////////////////////
+
+// Deque is a double ended queue backed by segment files in a directory. It is
+// not safe for concurrent use, and a directory must not be opened by more than
+// one deque at a time.
+type Deque struct {
+	dir    string
+	opts   Options
+	head   *_Chunk          // front chunk
+	headIn *_Chunk          // chunk behind the front chunk
+	tailIn *_Chunk          // chunk in front of the back chunk
+	tail   *_Chunk          // back chunk
+	seqs   *deque_int.Deque // sequence numbers of the spilled segments
+	lo, hi int              // lowest and highest sequence numbers in use
+	size   int
+	closed bool
+}
+
+// Open returns a deque backed by the segment files in dir, creating the
+// directory if needed. The deque holds the items left in dir by a previous
+// deque. opts may be nil.
+func Open(dir string, opts *Options) (*Deque, error) {
+	d := &Deque{
+		dir:    dir,
+		head:   newChunk(),
+		headIn: newChunk(),
+		tailIn: newChunk(),
+		tail:   newChunk(),
+		seqs:   deque_int.New(),
+		hi:     -1,
+	}
+	if opts != nil {
+		d.opts = *opts
+	}
+	if d.opts.ChunkSize <= 0 {
+		d.opts.ChunkSize = DefaultChunkSize
+	}
+	if d.opts.Codec == nil {
+		d.opts.Codec = BytesCodec{}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	seqs, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, seq := range seqs {
+		count, err := segmentCount(segmentPath(dir, seq))
+		if err != nil {
+			return nil, err
+		}
+		d.seqs.PushBack(seq)
+		d.size += count
+	}
+	if len(seqs) > 0 {
+		d.lo, d.hi = seqs[0], seqs[len(seqs)-1]
+	}
+	return d, nil
+}
+
+// PushFront adds an item to the front of the deque. When the front chunk is
+// full, it takes the place of the chunk behind it, which is spilled to a
+// segment file first.
+func (d *Deque) PushFront(item interface{}) error {
+	if d.closed {
+		return ErrClosed
+	}
+	if d.head.items.Size() >= d.opts.ChunkSize {
+		if d.headIn.items.Size() > 0 {
+			if err := d.spillFront(d.headIn); err != nil {
+				return err
+			}
+		}
+		d.head, d.headIn = d.headIn, d.head
+	}
+	d.head.items.PushFront(item)
+	d.size++
+	return nil
+}
+
+// PushBack adds an item to the back of the deque. When the back chunk is full,
+// it takes the place of the chunk in front of it, which is spilled to a
+// segment file first.
+func (d *Deque) PushBack(item interface{}) error {
+	if d.closed {
+		return ErrClosed
+	}
+	if d.tail.items.Size() >= d.opts.ChunkSize {
+		if d.tailIn.items.Size() > 0 {
+			if err := d.spillBack(d.tailIn); err != nil {
+				return err
+			}
+		}
+		d.tail, d.tailIn = d.tailIn, d.tail
+	}
+	d.tail.items.PushBack(item)
+	d.size++
+	return nil
+}
+
+// PopFront removes and returns the item from the front of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) PopFront() (interface{}, error) {
+	src, err := d.front()
+	if src == nil || err != nil {
+		return nil, err
+	}
+	item := src.PopFront()
+	d.size--
+	return item, d.consumed()
+}
+
+// PopBack removes and returns the item from the back of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) PopBack() (interface{}, error) {
+	src, err := d.back()
+	if src == nil || err != nil {
+		return nil, err
+	}
+	item := src.PopBack()
+	d.size--
+	return item, d.consumed()
+}
+
+// FrontItem returns the item at the front of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) FrontItem() (interface{}, error) {
+	src, err := d.front()
+	if src == nil || err != nil {
+		return nil, err
+	}
+	return src.FrontItem(), nil
+}
+
+// BackItem returns the item at the back of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) BackItem() (interface{}, error) {
+	src, err := d.back()
+	if src == nil || err != nil {
+		return nil, err
+	}
+	return src.BackItem(), nil
+}
+
+// Size returns the number of items in the deque, in memory and on disk.
+func (d *Deque) Size() int {
+	return d.size
+}
+
+// Segments returns the number of segment files held by the deque.
+func (d *Deque) Segments() int {
+	return d.seqs.Size()
+}
+
+// Flush writes the in-memory chunks to segment files, so that all items of the
+// deque survive a crash.
+func (d *Deque) Flush() error {
+	if d.closed {
+		return ErrClosed
+	}
+	for _, c := range []*_Chunk{d.headIn, d.head} {
+		if c.items.Size() > 0 {
+			if err := d.spillFront(c); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range []*_Chunk{d.tailIn, d.tail} {
+		if c.items.Size() > 0 {
+			if err := d.spillBack(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close flushes the deque and releases it. The items remain in the directory
+// for the next Open.
+func (d *Deque) Close() error {
+	if d.closed {
+		return ErrClosed
+	}
+	err := d.Flush()
+	d.closed = true
+	return err
+}
+
+// front returns the chunk holding the front item, loading it from disk if
+// needed, or nil if the deque is empty.
+func (d *Deque) front() (*deque.Deque, error) {
+	switch {
+	case d.closed:
+		return nil, ErrClosed
+	case d.size == 0:
+		return nil, nil
+	case d.head.items.Size() > 0:
+		return d.head.items, nil
+	case d.headIn.items.Size() > 0:
+		d.head, d.headIn = d.headIn, d.head
+		return d.head.items, nil
+	case d.seqs.Size() > 0:
+		if err := d.load(d.head, d.seqs.FrontItem()); err != nil {
+			return nil, err
+		}
+		d.seqs.PopFront()
+		return d.head.items, nil
+	case d.tailIn.items.Size() > 0:
+		return d.tailIn.items, nil
+	}
+	return d.tail.items, nil
+}
+
+// back returns the chunk holding the back item, loading it from disk if
+// needed, or nil if the deque is empty.
+func (d *Deque) back() (*deque.Deque, error) {
+	switch {
+	case d.closed:
+		return nil, ErrClosed
+	case d.size == 0:
+		return nil, nil
+	case d.tail.items.Size() > 0:
+		return d.tail.items, nil
+	case d.tailIn.items.Size() > 0:
+		d.tail, d.tailIn = d.tailIn, d.tail
+		return d.tail.items, nil
+	case d.seqs.Size() > 0:
+		if err := d.load(d.tail, d.seqs.BackItem()); err != nil {
+			return nil, err
+		}
+		d.seqs.PopBack()
+		return d.tail.items, nil
+	case d.headIn.items.Size() > 0:
+		return d.headIn.items, nil
+	}
+	return d.head.items, nil
+}
+
+// load decodes the items of segment seq into the empty chunk dst.
+func (d *Deque) load(dst *_Chunk, seq int) error {
+	data, err := readSegment(segmentPath(d.dir, seq))
+	if err != nil {
+		return err
+	}
+	for _, b := range data {
+		item, err := d.opts.Codec.Decode(b)
+		if err != nil {
+			dst.items.Clear()
+			return err
+		}
+		dst.items.PushBack(item)
+	}
+	dst.seq, dst.loaded = seq, true
+	return nil
+}
+
+// consumed removes the segment files of chunks that have been consumed.
+func (d *Deque) consumed() error {
+	for _, c := range []*_Chunk{d.head, d.headIn, d.tailIn, d.tail} {
+		if c.items.Size() == 0 {
+			if err := d.clear(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// spillFront writes chunk c to a new segment in front of the others.
+func (d *Deque) spillFront(c *_Chunk) error {
+	seq := d.lo - 1
+	if err := d.spill(c, seq); err != nil {
+		return err
+	}
+	d.lo = seq
+	d.seqs.PushFront(seq)
+	return d.clear(c)
+}
+
+// spillBack writes chunk c to a new segment behind the others.
+func (d *Deque) spillBack(c *_Chunk) error {
+	seq := d.hi + 1
+	if err := d.spill(c, seq); err != nil {
+		return err
+	}
+	d.hi = seq
+	d.seqs.PushBack(seq)
+	return d.clear(c)
+}
+
+// clear empties chunk c, and removes the file of the segment it was loaded
+// from, if any.
+func (d *Deque) clear(c *_Chunk) error {
+	c.items.Clear()
+	if c.loaded {
+		c.loaded = false
+		return d.remove(c.seq)
+	}
+	return nil
+}
+
+// spill writes the items of chunk c to segment seq.
+func (d *Deque) spill(c *_Chunk, seq int) error {
+	data := make([][]byte, 0, c.items.Size())
+	for chunk := range c.items.Chunks() {
+		for _, item := range chunk {
+			b, err := d.opts.Codec.Encode(item)
+			if err != nil {
+				return err
+			}
+			data = append(data, b)
+		}
+	}
+	return writeSegment(segmentPath(d.dir, seq), data, d.opts.Sync == SyncSegments)
+}
+
+// remove removes the file of segment seq.
+func (d *Deque) remove(seq int) error {
+	if err := os.Remove(segmentPath(d.dir, seq)); err != nil {
+		return err
+	}
+	if d.opts.Sync == SyncSegments {
+		return syncDir(d.dir)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// diskdeque_test.go, jpad 2026
+
+package diskdeque_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/notnot/container/diskdeque"
+)
+
+func open(t testing.TB, dir string, opts *diskdeque.Options) *diskdeque.Deque {
+	t.Helper()
+	d, err := diskdeque.Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestEmpty(t *testing.T) {
+	d := open(t, t.TempDir(), nil)
+
+	if item, err := d.PopFront(); item != nil || err != nil {
+		t.Errorf("got: %v, %v, want: <nil>, <nil>", item, err)
+	}
+	if item, err := d.PopBack(); item != nil || err != nil {
+		t.Errorf("got: %v, %v, want: <nil>, <nil>", item, err)
+	}
+	if item, err := d.FrontItem(); item != nil || err != nil {
+		t.Errorf("got: %v, %v, want: <nil>, <nil>", item, err)
+	}
+	if d.Size() != 0 {
+		t.Errorf("got: %d, want: 0", d.Size())
+	}
+}
+
+func TestSpill(t *testing.T) {
+	const N = 100
+	d := open(t, t.TempDir(), &diskdeque.Options{ChunkSize: 10, Codec: diskdeque.StringCodec{}})
+
+	for i := 0; i < N; i++ {
+		d.PushBack(strconv.Itoa(i))
+	}
+	if d.Segments() != N/10-2 { // two chunks stay in memory
+		t.Errorf("got: %d, want: %d", d.Segments(), N/10-2)
+	}
+	if item, _ := d.BackItem(); item != strconv.Itoa(N-1) {
+		t.Errorf("got: %v, want: %d", item, N-1)
+	}
+	for i := 0; i < N; i++ {
+		item, err := d.PopFront()
+		if item != strconv.Itoa(i) || err != nil {
+			t.Fatalf("got: %v, %v, want: %d, <nil>", item, err, i)
+		}
+	}
+	if d.Segments() != 0 || d.Size() != 0 {
+		t.Errorf("got: %d segments, %d items, want: none", d.Segments(), d.Size())
+	}
+}
+
+// TestRandom checks random operations, including reopening the deque, against
+// a slice.
+func TestRandom(t *testing.T) {
+	const N = 5000
+	dir := t.TempDir()
+	opts := &diskdeque.Options{ChunkSize: 8, Codec: diskdeque.StringCodec{}}
+	d := open(t, dir, opts)
+	model := []string{}
+
+	for i := 0; i < N; i++ {
+		switch op := rand.Intn(100); {
+		case op < 30:
+			item := strconv.Itoa(i)
+			if err := d.PushFront(item); err != nil {
+				t.Fatal(err)
+			}
+			model = append([]string{item}, model...)
+		case op < 60:
+			item := strconv.Itoa(i)
+			if err := d.PushBack(item); err != nil {
+				t.Fatal(err)
+			}
+			model = append(model, item)
+		case op < 79:
+			item, err := d.PopFront()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(model) == 0 {
+				if item != nil {
+					t.Fatalf("%d: got: %v, want: <nil>", i, item)
+				}
+				break
+			}
+			if item != model[0] {
+				t.Fatalf("%d: got: %v, want: %s", i, item, model[0])
+			}
+			model = model[1:]
+		case op < 98:
+			item, err := d.PopBack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(model) == 0 {
+				if item != nil {
+					t.Fatalf("%d: got: %v, want: <nil>", i, item)
+				}
+				break
+			}
+			if item != model[len(model)-1] {
+				t.Fatalf("%d: got: %v, want: %s", i, item, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		case op < 99:
+			if err := d.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		default:
+			if err := d.Close(); err != nil {
+				t.Fatal(err)
+			}
+			d = open(t, dir, opts)
+		}
+		if d.Size() != len(model) {
+			t.Fatalf("%d: got: %d, want: %d", i, d.Size(), len(model))
+		}
+	}
+
+	// everything left must be on disk after Close
+	d.Close()
+	d = open(t, dir, opts)
+	for _, want := range model {
+		if item, err := d.PopFront(); item != want || err != nil {
+			t.Fatalf("got: %v, %v, want: %s, <nil>", item, err, want)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("got: %d files, want: 0", len(entries))
+	}
+}
+
+func TestReopen(t *testing.T) {
+	const N = 1000
+	dir := t.TempDir()
+	opts := &diskdeque.Options{ChunkSize: 16, Sync: diskdeque.SyncSegments}
+
+	d := open(t, dir, opts)
+	for i := 0; i < N; i++ {
+		d.PushBack([]byte{byte(i)})
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.PushBack([]byte{0}); err != diskdeque.ErrClosed {
+		t.Errorf("got: %v, want: %v", err, diskdeque.ErrClosed)
+	}
+
+	d = open(t, dir, opts)
+	if d.Size() != N {
+		t.Errorf("got: %d, want: %d", d.Size(), N)
+	}
+	for i := N - 1; i >= 0; i-- {
+		item, err := d.PopBack()
+		if err != nil || item.([]byte)[0] != byte(i) {
+			t.Fatalf("got: %v, %v, want: [%d], <nil>", item, err, byte(i))
+		}
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	opts := &diskdeque.Options{ChunkSize: 4, Codec: diskdeque.StringCodec{}}
+
+	d := open(t, dir, opts)
+	for i := 0; i < 4; i++ {
+		d.PushBack("item")
+	}
+	d.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	if len(files) != 1 {
+		t.Fatalf("got: %d files, want: 1", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	data[len(data)/2] ^= 0xff
+	os.WriteFile(files[0], data, 0644)
+
+	d = open(t, dir, opts)
+	if _, err := d.PopFront(); !errors.Is(err, diskdeque.ErrCorrupt) {
+		t.Errorf("got: %v, want: %v", err, diskdeque.ErrCorrupt)
+	}
+}
+
+func TestCodecError(t *testing.T) {
+	d := open(t, t.TempDir(), &diskdeque.Options{ChunkSize: 1})
+
+	d.PushBack("not bytes")
+	d.PushBack([]byte("fits"))
+	if err := d.PushBack([]byte("spills")); err == nil {
+		t.Errorf("got: <nil>, want: encode error")
+	}
+	if d.Size() != 2 {
+		t.Errorf("got: %d, want: 2", d.Size())
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkPushPop(b *testing.B) {
+	d := open(b, b.TempDir(), nil)
+	item := make([]byte, 64)
+	for i := 0; i < b.N; i++ {
+		d.PushBack(item)
+	}
+	for i := 0; i < b.N; i++ {
+		d.PopFront()
+	}
+}
+
+// BenchmarkPushPop_boundary pops and pushes around the boundary of a full back
+// chunk, which must not go to disk.
+func BenchmarkPushPop_boundary(b *testing.B) {
+	d := open(b, b.TempDir(), &diskdeque.Options{ChunkSize: 64})
+	item := make([]byte, 64)
+	for i := 0; i < 3*64; i++ {
+		d.PushBack(item)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d.PushBack(item)
+		d.PopBack()
+		d.PopBack()
+		d.PushBack(item)
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleOpen() {
+	dir, _ := os.MkdirTemp("", "diskdeque")
+	defer os.RemoveAll(dir)
+	opts := &diskdeque.Options{ChunkSize: 2, Codec: diskdeque.StringCodec{}}
+
+	d, _ := diskdeque.Open(dir, opts)
+	for _, item := range []string{"a", "b", "c", "d", "e"} {
+		d.PushBack(item)
+	}
+	d.Close()
+
+	// the items survive reopening
+	d, _ = diskdeque.Open(dir, opts)
+	for d.Size() > 0 {
+		item, _ := d.PopFront()
+		fmt.Print(item)
+	}
+	fmt.Println()
+
+	// Output:
+	// abcde
+}
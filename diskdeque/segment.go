@@ -0,0 +1,167 @@
+// segment.go, jpad 2026
+
+package diskdeque
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A segment file holds the encoded items of one spilled chunk:
+//
+//	count   uvarint
+//	items   count times: length uvarint, data [length]byte
+//	crc     uint32, big endian, IEEE checksum of the preceding bytes
+//
+// Segments are written to a temporary file first and renamed into place, so a
+// segment file is either complete or absent.
+
+const (
+	segmentExt = ".seg"
+	tmpExt     = ".tmp"
+)
+
+// ErrCorrupt is returned when a segment file fails its checksum or cannot be
+// parsed.
+var ErrCorrupt = errors.New("diskdeque: corrupt segment")
+
+func segmentPath(dir string, seq int) string {
+	return filepath.Join(dir, strconv.Itoa(seq)+segmentExt)
+}
+
+// listSegments returns the sequence numbers of the segments in dir in
+// ascending order, removing leftover temporary files.
+func listSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	seqs := []int{}
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, tmpExt):
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(name, segmentExt):
+			seq, err := strconv.Atoi(strings.TrimSuffix(name, segmentExt))
+			if err != nil {
+				continue // not ours
+			}
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Ints(seqs)
+	return seqs, nil
+}
+
+// writeSegment writes the encoded items to the segment file at path. With
+// sync set, the file and its directory are fsynced before returning.
+func writeSegment(path string, items [][]byte, sync bool) (err error) {
+	tmp := path + tmpExt
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	crc := crc32.NewIEEE()
+	w := bufio.NewWriter(io.MultiWriter(f, crc))
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], uint64(len(items)))])
+	for _, item := range items {
+		w.Write(buf[:binary.PutUvarint(buf[:], uint64(len(item)))])
+		w.Write(item)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = binary.Write(f, binary.BigEndian, crc.Sum32()); err != nil {
+		return err
+	}
+	if sync {
+		if err = f.Sync(); err != nil {
+			return err
+		}
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if sync {
+		return syncDir(filepath.Dir(path))
+	}
+	return nil
+}
+
+// readSegment returns the encoded items of the segment file at path.
+func readSegment(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: %s: short file", ErrCorrupt, path)
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return nil, fmt.Errorf("%w: %s: checksum mismatch", ErrCorrupt, path)
+	}
+
+	count, n := binary.Uvarint(body)
+	if n <= 0 || count > uint64(len(body)) {
+		return nil, fmt.Errorf("%w: %s: bad item count", ErrCorrupt, path)
+	}
+	body = body[n:]
+	items := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		size, n := binary.Uvarint(body)
+		if n <= 0 || size > uint64(len(body)-n) {
+			return nil, fmt.Errorf("%w: %s: bad item length", ErrCorrupt, path)
+		}
+		items = append(items, body[n:n+int(size)])
+		body = body[n+int(size):]
+	}
+	return items, nil
+}
+
+// segmentCount returns the number of items in the segment file at path
+// without reading the items.
+func segmentCount(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	count, err := binary.ReadUvarint(bufio.NewReader(f))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: bad item count", ErrCorrupt, path)
+	}
+	return int(count), nil
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
@@ -0,0 +1,312 @@
+// durable.go, jpad 2026
+
+/*
+Package durable implements a double ended queue that survives crashes. Every
+modification of the in-memory deque is first appended to a checksummed
+write-ahead log in a directory. The log is compacted into a snapshot of the
+items from time to time, and the snapshot and log are replayed when the
+directory is opened again.
+
+A crash may tear the record being written; on open, the log is truncated to
+its last complete record, so that the deque reflects every operation that
+returned without error, up to the last fsync of the log.
+*/
+package durable
+
+import (
+	"errors"
+	"os"
+
+	"github.com/notnot/container/deque"
+	"github.com/notnot/container/diskdeque"
+)
+
+// DefaultCompactEvery is the number of log records after which the log is
+// compacted when Options.CompactEvery is not set.
+const DefaultCompactEvery = 10000
+
+// ErrClosed is returned when a closed deque is used.
+var ErrClosed = errors.New("durable: deque is closed")
+
+// Options configure a deque. The zero value selects the defaults.
+type Options struct {
+	// Codec converts items to and from their logged form; default
+	// diskdeque.BytesCodec.
+	Codec diskdeque.Codec
+	// Sync fsyncs the log after every operation. Without it, operations can
+	// be lost on a crash of the machine, though not of the process.
+	Sync bool
+	// CompactEvery is the number of log records after which the log is
+	// compacted into a snapshot; default DefaultCompactEvery, negative for
+	// never.
+	CompactEvery int
+}
+
+//// Deque /////////////////////////////////////////////////////////////////////
+
+// Deque is a double ended queue backed by a write-ahead log. It is not safe for
+// concurrent use, and a directory must not be opened by more than one deque at
+// a time. Pushed items must not be modified afterwards.
+type Deque struct {
+	dir     string
+	opts    Options
+	deque   *deque.Deque
+	log     *os.File
+	gen     int   // log generation
+	offset  int64 // log size
+	records int   // log records since the last compaction
+	buf     []byte
+	err     error // sticky error of a log that could not be repaired
+}
+
+// Open returns a deque restored from the snapshot and log in dir, creating the
+// directory if needed. opts may be nil.
+func Open(dir string, opts *Options) (*Deque, error) {
+	d := &Deque{dir: dir, deque: deque.New()}
+	if opts != nil {
+		d.opts = *opts
+	}
+	if d.opts.Codec == nil {
+		d.opts.Codec = diskdeque.BytesCodec{}
+	}
+	if d.opts.CompactEvery == 0 {
+		d.opts.CompactEvery = DefaultCompactEvery
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	gen, items, err := readSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := removeStaleLogs(dir, gen); err != nil {
+		return nil, err
+	}
+	d.gen = gen
+	for _, data := range items {
+		item, err := d.opts.Codec.Decode(data)
+		if err != nil {
+			return nil, err
+		}
+		d.deque.PushBack(item)
+	}
+
+	// replay the log up to its last complete record
+	data, err := os.ReadFile(logPath(dir, gen))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for {
+		op, item, n := parseRecord(data[d.offset:])
+		if n == 0 {
+			break
+		}
+		if err := d.apply(op, item); err != nil {
+			return nil, err
+		}
+		d.offset += int64(n)
+		d.records++
+	}
+
+	d.log, err = os.OpenFile(logPath(dir, gen), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if d.offset < int64(len(data)) { // drop the torn tail
+		if err := d.log.Truncate(d.offset); err != nil {
+			d.log.Close()
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// PushFront adds an item to the front of the deque.
+func (d *Deque) PushFront(item interface{}) error {
+	return d.push(opPushFront, item)
+}
+
+// PushBack adds an item to the back of the deque.
+func (d *Deque) PushBack(item interface{}) error {
+	return d.push(opPushBack, item)
+}
+
+// PopFront removes and returns the item from the front of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) PopFront() (interface{}, error) {
+	return d.pop(opPopFront)
+}
+
+// PopBack removes and returns the item from the back of the deque.
+// Returns nil when the deque is empty.
+func (d *Deque) PopBack() (interface{}, error) {
+	return d.pop(opPopBack)
+}
+
+// FrontItem returns the item at the front of the deque.
+func (d *Deque) FrontItem() interface{} {
+	return d.deque.FrontItem()
+}
+
+// BackItem returns the item at the back of the deque.
+func (d *Deque) BackItem() interface{} {
+	return d.deque.BackItem()
+}
+
+// Size returns the number of items in the deque.
+func (d *Deque) Size() int {
+	return d.deque.Size()
+}
+
+// Clear removes all items from the deque.
+func (d *Deque) Clear() error {
+	if err := d.append(opClear, nil); err != nil {
+		return err
+	}
+	d.deque.Clear()
+	return d.compactIfDue()
+}
+
+// Compact writes a snapshot of the items and starts a new, empty log.
+func (d *Deque) Compact() error {
+	if err := d.usable(); err != nil {
+		return err
+	}
+	items := make([][]byte, 0, d.deque.Size())
+	for chunk := range d.deque.Chunks() {
+		for _, item := range chunk {
+			data, err := d.opts.Codec.Encode(item)
+			if err != nil {
+				return err
+			}
+			items = append(items, data)
+		}
+	}
+	if err := writeSnapshot(d.dir, d.gen+1, items); err != nil {
+		return err
+	}
+
+	// from here on the snapshot is in effect, even if the new log is not
+	log, err := os.OpenFile(logPath(d.dir, d.gen+1), os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		d.err = err
+		return err
+	}
+	d.log.Close()
+	os.Remove(logPath(d.dir, d.gen))
+	d.log = log
+	d.gen++
+	d.offset = 0
+	d.records = 0
+	return syncDir(d.dir)
+}
+
+// Close closes the log. The items remain in the directory for the next Open.
+func (d *Deque) Close() error {
+	if d.log == nil {
+		return ErrClosed
+	}
+	err := d.log.Sync()
+	if cerr := d.log.Close(); err == nil {
+		err = cerr
+	}
+	d.log = nil
+	return err
+}
+
+func (d *Deque) push(op byte, item interface{}) error {
+	data, err := d.opts.Codec.Encode(item)
+	if err != nil {
+		return err
+	}
+	if err := d.append(op, data); err != nil {
+		return err
+	}
+	if op == opPushFront {
+		d.deque.PushFront(item)
+	} else {
+		d.deque.PushBack(item)
+	}
+	return d.compactIfDue()
+}
+
+func (d *Deque) pop(op byte) (interface{}, error) {
+	if err := d.usable(); err != nil {
+		return nil, err
+	}
+	if d.deque.Size() == 0 {
+		return nil, nil
+	}
+	if err := d.append(op, nil); err != nil {
+		return nil, err
+	}
+	var item interface{}
+	if op == opPopFront {
+		item = d.deque.PopFront()
+	} else {
+		item = d.deque.PopBack()
+	}
+	return item, d.compactIfDue()
+}
+
+// append appends a record to the log. A failed write is cut off the log again,
+// so that it does not hide the records written after it.
+func (d *Deque) append(op byte, data []byte) error {
+	if err := d.usable(); err != nil {
+		return err
+	}
+	d.buf = appendRecord(d.buf[:0], op, data)
+	if _, err := d.log.Write(d.buf); err != nil {
+		if terr := d.log.Truncate(d.offset); terr != nil {
+			d.err = terr
+		}
+		return err
+	}
+	if d.opts.Sync {
+		if err := d.log.Sync(); err != nil {
+			d.err = err
+			return err
+		}
+	}
+	d.offset += int64(len(d.buf))
+	d.records++
+	return nil
+}
+
+// apply applies a replayed log record to the in-memory deque.
+func (d *Deque) apply(op byte, data []byte) error {
+	switch op {
+	case opPushFront, opPushBack:
+		item, err := d.opts.Codec.Decode(append([]byte(nil), data...))
+		if err != nil {
+			return err
+		}
+		if op == opPushFront {
+			d.deque.PushFront(item)
+		} else {
+			d.deque.PushBack(item)
+		}
+	case opPopFront:
+		d.deque.PopFront()
+	case opPopBack:
+		d.deque.PopBack()
+	case opClear:
+		d.deque.Clear()
+	}
+	return nil
+}
+
+func (d *Deque) compactIfDue() error {
+	if d.opts.CompactEvery > 0 && d.records >= d.opts.CompactEvery {
+		return d.Compact()
+	}
+	return nil
+}
+
+func (d *Deque) usable() error {
+	if d.log == nil {
+		return ErrClosed
+	}
+	return d.err
+}
@@ -0,0 +1,221 @@
+// durable_test.go, jpad 2026
+
+package durable_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/notnot/container/diskdeque"
+	"github.com/notnot/container/durable"
+)
+
+func open(t *testing.T, dir string, opts *durable.Options) *durable.Deque {
+	t.Helper()
+	d, err := durable.Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// randomOp applies a random operation to the deque and to its model.
+func randomOp(t *testing.T, d *durable.Deque, model []string, i int) []string {
+	t.Helper()
+	var err error
+	switch op := rand.Intn(100); {
+	case op < 35:
+		err = d.PushFront(strconv.Itoa(i))
+		model = append([]string{strconv.Itoa(i)}, model...)
+	case op < 70:
+		err = d.PushBack(strconv.Itoa(i))
+		model = append(model, strconv.Itoa(i))
+	case op < 84:
+		var item interface{}
+		item, err = d.PopFront()
+		if len(model) > 0 {
+			if item != model[0] {
+				t.Fatalf("%d: got: %v, want: %s", i, item, model[0])
+			}
+			model = model[1:]
+		}
+	case op < 99:
+		var item interface{}
+		item, err = d.PopBack()
+		if len(model) > 0 {
+			if item != model[len(model)-1] {
+				t.Fatalf("%d: got: %v, want: %s", i, item, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		}
+	default:
+		err = d.Clear()
+		model = nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+// check checks the items of the deque against the model, emptying the deque.
+func check(t *testing.T, d *durable.Deque, model []string) {
+	t.Helper()
+	if d.Size() != len(model) {
+		t.Fatalf("got: %d, want: %d", d.Size(), len(model))
+	}
+	for _, want := range model {
+		if item, err := d.PopFront(); item != want || err != nil {
+			t.Fatalf("got: %v, %v, want: %s, <nil>", item, err, want)
+		}
+	}
+}
+
+func logFile(t *testing.T, dir string) string {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(dir, "wal.*"))
+	if len(files) != 1 {
+		t.Fatalf("got: %d logs, want: 1", len(files))
+	}
+	return files[0]
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestReopen(t *testing.T) {
+	const N = 5000
+	dir := t.TempDir()
+	opts := &durable.Options{Codec: diskdeque.StringCodec{}, CompactEvery: 300}
+
+	d := open(t, dir, opts)
+	model := []string{}
+	for i := 0; i < N; i++ {
+		model = randomOp(t, d, model, i)
+		if i%1000 == 999 {
+			d.Close()
+			d = open(t, dir, opts)
+		}
+	}
+	d.Close()
+
+	d = open(t, dir, opts)
+	check(t, d, model)
+	d.Close()
+	if err := d.Close(); err != durable.ErrClosed {
+		t.Errorf("got: %v, want: %v", err, durable.ErrClosed)
+	}
+	if err := d.PushBack("x"); err != durable.ErrClosed {
+		t.Errorf("got: %v, want: %v", err, durable.ErrClosed)
+	}
+}
+
+// TestTornWrite truncates the log at random offsets, as a crash during a write
+// would, and checks that the deque recovers the operations whose records are
+// complete.
+func TestTornWrite(t *testing.T) {
+	const N = 300
+	for _, compactEvery := range []int{-1, 200} {
+		dir := t.TempDir()
+		opts := &durable.Options{Codec: diskdeque.StringCodec{}, CompactEvery: compactEvery}
+		// recovering must leave the log in place for the next cut
+		noCompact := &durable.Options{Codec: diskdeque.StringCodec{}, CompactEvery: -1}
+
+		// remember the log and model after every operation
+		d := open(t, dir, opts)
+		names := []string{logFile(t, dir)}
+		sizes := []int64{0}
+		models := [][]string{nil}
+		model := []string{}
+		for i := 0; i < N; i++ {
+			model = randomOp(t, d, model, i)
+			name := logFile(t, dir)
+			info, _ := os.Stat(name)
+			names = append(names, name)
+			sizes = append(sizes, info.Size())
+			models = append(models, append([]string(nil), model...))
+		}
+		d.Close()
+		name := names[N]
+		log, _ := os.ReadFile(name)
+
+		for k := 0; k < 50; k++ {
+			cut := rand.Int63n(int64(len(log)) + 1)
+			os.WriteFile(name, log[:cut], 0644)
+
+			// the deque holds the model of the last operation logged
+			// completely
+			i := N
+			for names[i] != name || sizes[i] > cut {
+				i--
+			}
+			want := append(models[i], "after")
+
+			// the repaired log must take new records
+			d := open(t, dir, noCompact)
+			if err := d.PushBack("after"); err != nil {
+				t.Fatal(err)
+			}
+			d.Close()
+			d = open(t, dir, noCompact)
+			check(t, d, want)
+			d.Close()
+		}
+	}
+}
+
+func TestCorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	d := open(t, dir, nil)
+	d.PushBack([]byte("item"))
+	d.Compact()
+	d.Close()
+
+	name := filepath.Join(dir, "snapshot")
+	data, _ := os.ReadFile(name)
+	data[0] ^= 0xff
+	os.WriteFile(name, data, 0644)
+	if _, err := durable.Open(dir, nil); err == nil {
+		t.Errorf("got: <nil>, want: %v", durable.ErrCorrupt)
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkPushPop(b *testing.B) {
+	d, _ := durable.Open(b.TempDir(), nil)
+	item := make([]byte, 64)
+	for i := 0; i < b.N; i++ {
+		d.PushBack(item)
+		d.PopFront()
+	}
+	d.Close()
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleOpen() {
+	dir, _ := os.MkdirTemp("", "durable")
+	defer os.RemoveAll(dir)
+	opts := &durable.Options{Codec: diskdeque.StringCodec{}}
+
+	d, _ := durable.Open(dir, opts)
+	d.PushBack("b")
+	d.PushBack("c")
+	d.PushFront("a")
+	d.PopBack()
+	d.Close()
+
+	d, _ = durable.Open(dir, opts)
+	for d.Size() > 0 {
+		item, _ := d.PopFront()
+		fmt.Print(item)
+	}
+	fmt.Println()
+
+	// Output:
+	// ab
+}
@@ -0,0 +1,206 @@
+// log.go, jpad 2026
+
+package durable
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The write-ahead log holds one record per operation:
+//
+//	op      byte
+//	length  uvarint, push operations only
+//	data    [length]byte, push operations only
+//	crc     uint32, big endian, IEEE checksum of the preceding record bytes
+//
+// The snapshot holds the items of the deque at the start of a log generation:
+//
+//	gen     uvarint, generation of the log that continues the snapshot
+//	count   uvarint
+//	items   count times: length uvarint, data [length]byte
+//	crc     uint32, big endian, IEEE checksum of the preceding bytes
+//
+// A snapshot is written to a temporary file and renamed into place, and only
+// then is the log of the next generation started. A log of another generation
+// than the snapshot's is stale and removed on open.
+
+const (
+	opPushFront byte = iota + 1
+	opPushBack
+	opPopFront
+	opPopBack
+	opClear
+)
+
+const (
+	snapshotName = "snapshot"
+	logPrefix    = "wal."
+)
+
+// ErrCorrupt is returned when the snapshot fails its checksum or cannot be
+// parsed. A damaged log tail is not an error: it is the expected result of a
+// crash during a write and is truncated on open.
+var ErrCorrupt = errors.New("durable: corrupt snapshot")
+
+func logPath(dir string, gen int) string {
+	return filepath.Join(dir, logPrefix+strconv.Itoa(gen))
+}
+
+// appendRecord appends the record of an operation to buf.
+func appendRecord(buf []byte, op byte, data []byte) []byte {
+	start := len(buf)
+	buf = append(buf, op)
+	if op == opPushFront || op == opPushBack {
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+	}
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf[start:]))
+}
+
+// parseRecord parses the record at the start of buf. It returns the record
+// length, or 0 if buf does not start with a complete, valid record.
+func parseRecord(buf []byte) (op byte, data []byte, n int) {
+	if len(buf) == 0 {
+		return 0, nil, 0
+	}
+	op, n = buf[0], 1
+	switch op {
+	case opPushFront, opPushBack:
+		size, m := binary.Uvarint(buf[n:])
+		if m <= 0 || size > uint64(len(buf)-n-m) {
+			return 0, nil, 0
+		}
+		n += m
+		data = buf[n : n+int(size)]
+		n += int(size)
+	case opPopFront, opPopBack, opClear:
+	default:
+		return 0, nil, 0
+	}
+	if len(buf)-n < 4 || crc32.ChecksumIEEE(buf[:n]) != binary.BigEndian.Uint32(buf[n:]) {
+		return 0, nil, 0
+	}
+	return op, data, n + 4
+}
+
+// writeSnapshot writes the encoded items as the snapshot of dir, continued by
+// the log of generation gen.
+func writeSnapshot(dir string, gen int, items [][]byte) (err error) {
+	path := filepath.Join(dir, snapshotName)
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	crc := crc32.NewIEEE()
+	w := bufio.NewWriter(io.MultiWriter(f, crc))
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], uint64(gen))])
+	w.Write(buf[:binary.PutUvarint(buf[:], uint64(len(items)))])
+	for _, item := range items {
+		w.Write(buf[:binary.PutUvarint(buf[:], uint64(len(item)))])
+		w.Write(item)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = binary.Write(f, binary.BigEndian, crc.Sum32()); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// readSnapshot returns the log generation and the encoded items of the
+// snapshot of dir. A missing snapshot is an empty one at generation 0.
+func readSnapshot(dir string) (gen int, items [][]byte, err error) {
+	path := filepath.Join(dir, snapshotName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 4 {
+		return 0, nil, fmt.Errorf("%w: short file", ErrCorrupt)
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return 0, nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+
+	g, n := binary.Uvarint(body)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("%w: bad generation", ErrCorrupt)
+	}
+	body = body[n:]
+	count, n := binary.Uvarint(body)
+	if n <= 0 || count > uint64(len(body)) {
+		return 0, nil, fmt.Errorf("%w: bad item count", ErrCorrupt)
+	}
+	body = body[n:]
+	items = make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		size, n := binary.Uvarint(body)
+		if n <= 0 || size > uint64(len(body)-n) {
+			return 0, nil, fmt.Errorf("%w: bad item length", ErrCorrupt)
+		}
+		items = append(items, body[n:n+int(size)])
+		body = body[n+int(size):]
+	}
+	return int(g), items, nil
+}
+
+// removeStaleLogs removes the logs of dir other than the one of generation
+// gen, and leftover temporary files.
+func removeStaleLogs(dir string, gen int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		stale := strings.HasSuffix(name, ".tmp") ||
+			strings.HasPrefix(name, logPrefix) && name != logPrefix+strconv.Itoa(gen)
+		if stale {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
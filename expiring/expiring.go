@@ -0,0 +1,127 @@
+// expiring.go, jpad 2026
+
+/*
+Package expiring implements a queue whose items expire after a time to live.
+Each item is stamped with the current time when it is pushed to the back, and
+expired items are discarded from the front as the queue is read, or in bulk by
+ExpireBefore. The clock is injectable, so expiry can be tested without
+sleeping.
+*/
+package expiring
+
+import (
+	"sort"
+	"time"
+
+	"github.com/notnot/container/deque"
+)
+
+//// Deque /////////////////////////////////////////////////////////////////////
+
+// Deque is a queue of items that expire a fixed time after they were pushed.
+// Items are ordered by their time stamps, which requires a clock that doesn't
+// run backwards.
+type Deque struct {
+	entries *deque.Deque // of entry, in time stamp order
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+type entry struct {
+	item  interface{}
+	stamp time.Time
+}
+
+// New returns a pointer to an empty deque whose items expire ttl after they
+// were pushed. now is the clock used to stamp and expire items; nil selects
+// time.Now.
+func New(ttl time.Duration, now func() time.Time) *Deque {
+	if now == nil {
+		now = time.Now
+	}
+	return &Deque{entries: deque.New(), ttl: ttl, now: now}
+}
+
+// PushBack stamps an item with the current time and adds it to the back of the
+// deque.
+func (d *Deque) PushBack(item interface{}) {
+	d.entries.PushBack(entry{item: item, stamp: d.now()})
+}
+
+// PopFront discards the expired items, then removes and returns the item from
+// the front of the deque. Returns nil when no unexpired item is left.
+func (d *Deque) PopFront() interface{} {
+	d.expire()
+	if d.entries.Size() == 0 {
+		return nil
+	}
+	return d.entries.PopFront().(entry).item
+}
+
+// PopBack removes and returns the item from the back of the deque, which is
+// the last to expire. Returns nil when no unexpired item is left.
+func (d *Deque) PopBack() interface{} {
+	d.expire()
+	if d.entries.Size() == 0 {
+		return nil
+	}
+	return d.entries.PopBack().(entry).item
+}
+
+// FrontItem discards the expired items, then returns the item at the front of
+// the deque. Returns nil when no unexpired item is left.
+func (d *Deque) FrontItem() interface{} {
+	d.expire()
+	if d.entries.Size() == 0 {
+		return nil
+	}
+	return d.entries.FrontItem().(entry).item
+}
+
+// FrontStamp discards the expired items, then returns the time stamp of the
+// item at the front of the deque. Returns the zero time when no unexpired
+// item is left.
+func (d *Deque) FrontStamp() time.Time {
+	d.expire()
+	if d.entries.Size() == 0 {
+		return time.Time{}
+	}
+	return d.entries.FrontItem().(entry).stamp
+}
+
+// Size discards the expired items, then returns the number of items in the
+// deque.
+func (d *Deque) Size() int {
+	d.expire()
+	return d.entries.Size()
+}
+
+// ExpireBefore discards all items stamped before t, and returns the number of
+// discarded items. Chunks of items that are all older than t are dropped as a
+// whole; only the chunk holding the boundary is searched.
+func (d *Deque) ExpireBefore(t time.Time) int {
+	n := 0
+	for chunk := range d.entries.Chunks() {
+		if chunk[len(chunk)-1].(entry).stamp.Before(t) { // whole chunk?
+			n += len(chunk)
+			continue
+		}
+		n += sort.Search(len(chunk), func(i int) bool {
+			return !chunk[i].(entry).stamp.Before(t)
+		})
+		break
+	}
+	return d.entries.DiscardFront(n)
+}
+
+// Clear removes all items from the deque.
+func (d *Deque) Clear() {
+	d.entries.Clear()
+}
+
+// expire discards the items that have outlived the time to live.
+func (d *Deque) expire() {
+	if d.entries.Size() > 0 {
+		d.ExpireBefore(d.now().Add(-d.ttl))
+	}
+}
@@ -0,0 +1,138 @@
+// expiring_test.go, jpad 2026
+
+package expiring_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/notnot/container/expiring"
+)
+
+// clock is a fake clock that only moves when told to.
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newClock() *clock {
+	return &clock{t: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestEmpty(t *testing.T) {
+	d := expiring.New(time.Second, nil)
+
+	if d.PopFront() != nil || d.PopBack() != nil || d.FrontItem() != nil {
+		t.Errorf("got: item, want: <nil>")
+	}
+	if !d.FrontStamp().IsZero() {
+		t.Errorf("got: %v, want: zero time", d.FrontStamp())
+	}
+	if d.Size() != 0 {
+		t.Errorf("got: %d, want: 0", d.Size())
+	}
+}
+
+func TestExpire(t *testing.T) {
+	c := newClock()
+	d := expiring.New(10*time.Second, c.now)
+
+	for i := 0; i < 5; i++ {
+		d.PushBack(i)
+		c.advance(time.Second)
+	}
+	// items 0..4 were stamped at 0s..4s, it is 5s now
+	if d.FrontItem() != 0 || d.Size() != 5 {
+		t.Errorf("got: %v of %d, want: 0 of 5", d.FrontItem(), d.Size())
+	}
+
+	c.advance(7 * time.Second) // 12s: 0 and 1 have expired
+	if d.FrontItem() != 2 {
+		t.Errorf("got: %v, want: 2", d.FrontItem())
+	}
+	if !d.FrontStamp().Equal(newClock().t.Add(2 * time.Second)) {
+		t.Errorf("got: %v, want: 2s", d.FrontStamp())
+	}
+	if item := d.PopFront(); item != 2 {
+		t.Errorf("got: %v, want: 2", item)
+	}
+	if item := d.PopBack(); item != 4 {
+		t.Errorf("got: %v, want: 4", item)
+	}
+
+	c.advance(time.Hour)
+	if d.Size() != 0 || d.PopFront() != nil {
+		t.Errorf("got: %d items, want: none", d.Size())
+	}
+}
+
+func TestExpireBefore(t *testing.T) {
+	const N = 1000
+	c := newClock()
+	d := expiring.New(time.Hour, c.now)
+	start := c.t
+
+	for i := 0; i < N; i++ {
+		d.PushBack(i)
+		if i%3 == 2 {
+			c.advance(time.Millisecond)
+		}
+	}
+
+	for _, ms := range []int{0, 1, 10, 11, 100, 333, 334} {
+		want := 3 * ms
+		if want > N {
+			want = N
+		}
+		before := d.Size()
+		expired := d.ExpireBefore(start.Add(time.Duration(ms) * time.Millisecond))
+		if before-expired != N-want {
+			t.Fatalf("%dms: got: %d left, want: %d", ms, before-expired, N-want)
+		}
+		if d.Size() > 0 && d.FrontItem() != want {
+			t.Fatalf("%dms: got: %v, want: %d", ms, d.FrontItem(), want)
+		}
+	}
+	if d.Size() != 0 {
+		t.Errorf("got: %d, want: 0", d.Size())
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkPushExpire(b *testing.B) {
+	c := newClock()
+	d := expiring.New(time.Second, c.now)
+	for i := 0; i < b.N; i++ {
+		d.PushBack(i)
+		c.advance(time.Millisecond)
+		d.FrontItem()
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleDeque() {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := expiring.New(time.Minute, func() time.Time { return now })
+
+	d.PushBack("a")
+	now = now.Add(30 * time.Second)
+	d.PushBack("b")
+	now = now.Add(45 * time.Second) // "a" is 75s old now
+
+	fmt.Println(d.Size(), d.PopFront())
+
+	// Output:
+	// 1 b
+}
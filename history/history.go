@@ -0,0 +1,130 @@
+// history.go, jpad 2026
+
+/*
+Package history implements an undo/redo history of commands.
+
+Commands are recorded with Do and grouped into steps by Checkpoint: Undo and
+Redo always revert or repeat a whole step, so an editor action that consists
+of several commands is undone at once. The history keeps a bounded number of
+steps; when it is exceeded, the oldest step falls off and can no longer be
+undone. Doing a new command after an undo discards the steps that could have
+been redone.
+*/
+package history
+
+import (
+	"github.com/notnot/container/deque"
+)
+
+// Command is an action that can be undone.
+type Command interface {
+	Do()
+	Undo()
+}
+
+// checkpoint marks the end of a step in the deques of a history.
+type checkpoint struct{}
+
+//// History ///////////////////////////////////////////////////////////////////
+
+// History is an undo/redo history of commands.
+type History struct {
+	done   *deque.Deque // commands and checkpoints, the latest at the back
+	undone *deque.Deque // undone commands and checkpoints, the next at the back
+	steps  int          // checkpoints in done
+	max    int
+}
+
+// New returns a pointer to an empty history that keeps at most max steps. A
+// max of 0 or less keeps all steps.
+func New(max int) *History {
+	return &History{done: deque.New(), undone: deque.New(), max: max}
+}
+
+// Do runs cmd and records it in the current step. The steps that could have
+// been redone are discarded.
+func (h *History) Do(cmd Command) {
+	cmd.Do()
+	h.done.PushBack(cmd)
+	h.undone.Clear()
+}
+
+// Checkpoint ends the current step, so that the commands done since the
+// previous checkpoint are undone and redone together. It does nothing when no
+// command was done since the previous checkpoint.
+func (h *History) Checkpoint() {
+	if !h.open() {
+		return
+	}
+	h.done.PushBack(checkpoint{})
+	h.steps++
+	for h.max > 0 && h.steps > h.max { // drop the oldest step
+		for {
+			if _, ok := h.done.PopFront().(checkpoint); ok {
+				break
+			}
+		}
+		h.steps--
+	}
+}
+
+// Undo ends the current step and undoes it, reverting its commands in reverse
+// order. Returns false when there is no step to undo.
+func (h *History) Undo() bool {
+	h.Checkpoint()
+	if h.steps == 0 {
+		return false
+	}
+	h.undone.PushBack(h.done.PopBack()) // the checkpoint
+	h.steps--
+	for h.open() {
+		cmd := h.done.PopBack().(Command)
+		cmd.Undo()
+		h.undone.PushBack(cmd)
+	}
+	return true
+}
+
+// Redo repeats the last undone step, doing its commands in their original
+// order. Returns false when there is no step to redo.
+func (h *History) Redo() bool {
+	if h.undone.Size() == 0 {
+		return false
+	}
+	for {
+		cmd, ok := h.undone.PopBack().(Command)
+		if !ok { // the checkpoint
+			break
+		}
+		cmd.Do()
+		h.done.PushBack(cmd)
+	}
+	h.Checkpoint()
+	return true
+}
+
+// CanUndo reports whether there is a step to undo.
+func (h *History) CanUndo() bool {
+	return h.steps > 0 || h.open()
+}
+
+// CanRedo reports whether there is a step to redo.
+func (h *History) CanRedo() bool {
+	return h.undone.Size() > 0
+}
+
+// Clear forgets all steps, without undoing them.
+func (h *History) Clear() {
+	h.done.Clear()
+	h.undone.Clear()
+	h.steps = 0
+}
+
+// open reports whether commands were done since the last checkpoint.
+func (h *History) open() bool {
+	if h.done.Size() == 0 {
+		return false
+	}
+	_, ok := h.done.BackItem().(Command)
+	return ok
+}
@@ -0,0 +1,150 @@
+// history_test.go, jpad 2026
+
+package history_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/notnot/container/history"
+)
+
+// appendCmd appends a string to a text, and undoes it by truncating.
+type appendCmd struct {
+	text *string
+	s    string
+}
+
+func (c appendCmd) Do()   { *c.text += c.s }
+func (c appendCmd) Undo() { *c.text = (*c.text)[:len(*c.text)-len(c.s)] }
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestEmpty(t *testing.T) {
+	h := history.New(10)
+
+	if h.CanUndo() || h.CanRedo() || h.Undo() || h.Redo() {
+		t.Errorf("got: step, want: none")
+	}
+	h.Checkpoint()
+	if h.CanUndo() {
+		t.Errorf("got: step, want: none")
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	text := ""
+	h := history.New(0)
+
+	h.Do(appendCmd{&text, "a"})
+	h.Checkpoint()
+	h.Do(appendCmd{&text, "b"})
+	h.Do(appendCmd{&text, "c"}) // "bc" is one step
+	h.Checkpoint()
+	h.Checkpoint()              // empty steps are not recorded
+	h.Do(appendCmd{&text, "d"}) // the open step counts too
+
+	for _, want := range []string{"abc", "a", ""} {
+		if !h.Undo() || text != want {
+			t.Errorf("undo: got: %q, want: %q", text, want)
+		}
+	}
+	if h.Undo() || h.CanUndo() {
+		t.Errorf("got: undo, want: none")
+	}
+	for _, want := range []string{"a", "abc", "abcd"} {
+		if !h.Redo() || text != want {
+			t.Errorf("redo: got: %q, want: %q", text, want)
+		}
+	}
+	if h.Redo() || h.CanRedo() {
+		t.Errorf("got: redo, want: none")
+	}
+
+	// a new command discards the redo steps
+	h.Undo()
+	h.Undo()
+	h.Do(appendCmd{&text, "x"})
+	if text != "ax" || h.CanRedo() {
+		t.Errorf("got: %q, %v, want: \"ax\", false", text, h.CanRedo())
+	}
+	h.Undo()
+	if text != "a" {
+		t.Errorf("got: %q, want: \"a\"", text)
+	}
+}
+
+func TestMax(t *testing.T) {
+	const M = 5
+	text := ""
+	h := history.New(M)
+
+	for i := 0; i < 3*M; i++ {
+		h.Do(appendCmd{&text, "x"})
+		h.Do(appendCmd{&text, "y"})
+		h.Checkpoint()
+	}
+	undone := 0
+	for h.Undo() {
+		undone++
+	}
+	if undone != M || len(text) != 2*(3*M-M) {
+		t.Errorf("got: %d steps, %d bytes, want: %d, %d", undone, len(text), M, 2*(3*M-M))
+	}
+	for h.Redo() {
+	}
+	if len(text) != 6*M {
+		t.Errorf("got: %d, want: %d", len(text), 6*M)
+	}
+}
+
+func TestClear(t *testing.T) {
+	text := ""
+	h := history.New(10)
+	h.Do(appendCmd{&text, "a"})
+	h.Undo()
+	h.Do(appendCmd{&text, "b"})
+	h.Clear()
+
+	if h.CanUndo() || h.CanRedo() || text != "b" {
+		t.Errorf("got: %q, %v, %v, want: \"b\", false, false", text, h.CanUndo(), h.CanRedo())
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkDoUndo(b *testing.B) {
+	text := ""
+	h := history.New(100)
+	for i := 0; i < b.N; i++ {
+		h.Do(appendCmd{&text, "x"})
+		h.Checkpoint()
+		if i%3 == 2 {
+			h.Undo()
+		}
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleHistory() {
+	text := ""
+	h := history.New(100)
+
+	h.Do(appendCmd{&text, "Hello"})
+	h.Checkpoint()
+	h.Do(appendCmd{&text, ","})
+	h.Do(appendCmd{&text, " world"})
+	h.Checkpoint()
+	fmt.Println(text)
+
+	h.Undo()
+	fmt.Println(text)
+	h.Redo()
+	fmt.Println(text)
+
+	// Output:
+	// Hello, world
+	// Hello
+	// Hello, world
+}
@@ -0,0 +1,191 @@
+// minmaxheap.go, jpad 2026
+
+/*
+Package minmaxheap implements a double ended priority queue as a min-max heap.
+Both the smallest and the largest item can be inspected in constant time and
+removed in logarithmic time, which makes it usable as a sorted deque.
+*/
+package minmaxheap
+
+import (
+	"math/bits"
+)
+
+//// Heap //////////////////////////////////////////////////////////////////////
+
+// Heap is a min-max heap that can handle items of any type. The items are
+// ordered by a less function supplied at construction.
+type Heap struct {
+	items []interface{}
+	less  func(a, b interface{}) bool
+}
+
+// New returns a pointer to an empty heap that orders its items with less.
+func New(less func(a, b interface{}) bool) *Heap {
+	return &Heap{less: less}
+}
+
+// PushItem adds an item to the heap.
+func (h *Heap) PushItem(item interface{}) {
+	h.items = append(h.items, item)
+	h.bubbleUp(len(h.items) - 1)
+}
+
+// PopMin removes and returns the smallest item of the heap.
+// Returns nil when the heap is empty.
+func (h *Heap) PopMin() interface{} {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return h.remove(0)
+}
+
+// PopMax removes and returns the largest item of the heap.
+// Returns nil when the heap is empty.
+func (h *Heap) PopMax() interface{} {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return h.remove(h.maxIndex())
+}
+
+// Min returns the smallest item of the heap.
+// Returns nil when the heap is empty.
+func (h *Heap) Min() interface{} {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return h.items[0]
+}
+
+// Max returns the largest item of the heap.
+// Returns nil when the heap is empty.
+func (h *Heap) Max() interface{} {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return h.items[h.maxIndex()]
+}
+
+// Size returns the number of items in the heap.
+func (h *Heap) Size() int {
+	return len(h.items)
+}
+
+// Clear removes all items from the heap.
+func (h *Heap) Clear() {
+	h.items = nil
+}
+
+// maxIndex returns the index of the largest item of a non-empty heap.
+func (h *Heap) maxIndex() int {
+	switch len(h.items) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.less(h.items[1], h.items[2]) {
+		return 2
+	}
+	return 1
+}
+
+// remove removes and returns the item at index i.
+func (h *Heap) remove(i int) interface{} {
+	item := h.items[i]
+	n := len(h.items) - 1
+	h.items[i] = h.items[n]
+	h.items[n] = nil // let go of the item
+	h.items = h.items[:n]
+	if i < n {
+		h.trickleDown(i)
+	}
+	return item
+}
+
+// minLevel reports whether index i lies on a min level of the heap. The root
+// level is a min level, after which min and max levels alternate.
+func minLevel(i int) bool {
+	return bits.Len(uint(i+1))%2 == 1
+}
+
+func (h *Heap) bubbleUp(i int) {
+	if i == 0 {
+		return
+	}
+	p := (i - 1) / 2
+	if minLevel(i) {
+		if h.less(h.items[p], h.items[i]) {
+			h.swap(i, p)
+			h.bubbleUpLevel(p, h.greater)
+		} else {
+			h.bubbleUpLevel(i, h.less)
+		}
+	} else {
+		if h.less(h.items[i], h.items[p]) {
+			h.swap(i, p)
+			h.bubbleUpLevel(p, h.less)
+		} else {
+			h.bubbleUpLevel(i, h.greater)
+		}
+	}
+}
+
+// bubbleUpLevel moves the item at index i up along its grandparents while it
+// comes before them in order.
+func (h *Heap) bubbleUpLevel(i int, before func(a, b interface{}) bool) {
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !before(h.items[i], h.items[g]) {
+			return
+		}
+		h.swap(i, g)
+		i = g
+	}
+}
+
+func (h *Heap) trickleDown(i int) {
+	if minLevel(i) {
+		h.trickleDownLevel(i, h.less)
+	} else {
+		h.trickleDownLevel(i, h.greater)
+	}
+}
+
+// trickleDownLevel moves the item at index i down the heap while one of its
+// children or grandchildren comes before it in order.
+func (h *Heap) trickleDownLevel(i int, before func(a, b interface{}) bool) {
+	n := len(h.items)
+	for {
+		// find the first in order among children and grandchildren
+		m := -1
+		for _, c := range [...]int{2*i + 1, 2*i + 2, 4*i + 3, 4*i + 4, 4*i + 5, 4*i + 6} {
+			if c >= n {
+				break
+			}
+			if m < 0 || before(h.items[c], h.items[m]) {
+				m = c
+			}
+		}
+		if m < 0 || !before(h.items[m], h.items[i]) {
+			return
+		}
+		h.swap(m, i)
+		if m <= 2*i+2 { // child
+			return
+		}
+		if p := (m - 1) / 2; before(h.items[p], h.items[m]) {
+			h.swap(m, p)
+		}
+		i = m
+	}
+}
+
+func (h *Heap) greater(a, b interface{}) bool {
+	return h.less(b, a)
+}
+
+func (h *Heap) swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
@@ -0,0 +1,153 @@
+// minmaxheap_test.go, jpad 2026
+
+package minmaxheap_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/notnot/container/minmaxheap"
+)
+
+func lessInt(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestEmpty(t *testing.T) {
+	heap := minmaxheap.New(lessInt)
+
+	if heap.Min() != nil {
+		t.Errorf("got: %v, want: <nil>", heap.Min())
+	}
+	if heap.Max() != nil {
+		t.Errorf("got: %v, want: <nil>", heap.Max())
+	}
+	if heap.PopMin() != nil {
+		t.Errorf("got: %v, want: <nil>", heap.PopMin())
+	}
+	if heap.PopMax() != nil {
+		t.Errorf("got: %v, want: <nil>", heap.PopMax())
+	}
+	if heap.Size() != 0 {
+		t.Errorf("got: %d, want: 0", heap.Size())
+	}
+}
+
+func TestPushPop(t *testing.T) {
+	heap := minmaxheap.New(lessInt)
+
+	for _, item := range []int{5, 1, 9, 3, 7} {
+		heap.PushItem(item)
+	}
+	if heap.Min() != 1 || heap.Max() != 9 {
+		t.Errorf("got: %v..%v, want: 1..9", heap.Min(), heap.Max())
+	}
+	if min := heap.PopMin(); min != 1 {
+		t.Errorf("got: %v, want: 1", min)
+	}
+	if max := heap.PopMax(); max != 9 {
+		t.Errorf("got: %v, want: 9", max)
+	}
+	if heap.Size() != 3 {
+		t.Errorf("got: %d, want: 3", heap.Size())
+	}
+	heap.Clear()
+	if heap.Size() != 0 {
+		t.Errorf("got: %d, want: 0", heap.Size())
+	}
+}
+
+func TestPushPopRandom(t *testing.T) {
+	const N = 1000
+	heap := minmaxheap.New(lessInt)
+	sorted := []int{}
+
+	for i := 0; i < 10*N; i++ {
+		switch {
+		case rand.Intn(3) > 0 || len(sorted) == 0:
+			item := rand.Intn(N)
+			heap.PushItem(item)
+			sorted = append(sorted, item)
+			sort.Ints(sorted)
+		case rand.Intn(2) == 0:
+			if min := heap.PopMin(); min != sorted[0] {
+				t.Fatalf("got: %v, want: %d", min, sorted[0])
+			}
+			sorted = sorted[1:]
+		default:
+			if max := heap.PopMax(); max != sorted[len(sorted)-1] {
+				t.Fatalf("got: %v, want: %d", max, sorted[len(sorted)-1])
+			}
+			sorted = sorted[:len(sorted)-1]
+		}
+
+		if heap.Size() != len(sorted) {
+			t.Fatalf("got: %d, want: %d", heap.Size(), len(sorted))
+		}
+		if len(sorted) > 0 {
+			if heap.Min() != sorted[0] {
+				t.Fatalf("got: %v, want: %d", heap.Min(), sorted[0])
+			}
+			if heap.Max() != sorted[len(sorted)-1] {
+				t.Fatalf("got: %v, want: %d", heap.Max(), sorted[len(sorted)-1])
+			}
+		}
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkPushPopMin_1000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		heap := minmaxheap.New(lessInt)
+		for i := 0; i < 1000; i++ {
+			heap.PushItem(i)
+		}
+
+		sum := 0
+		for i := 0; i < 1000; i++ {
+			sum += heap.PopMin().(int)
+		}
+	}
+}
+
+func BenchmarkPushPopMax_1000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		heap := minmaxheap.New(lessInt)
+		for i := 0; i < 1000; i++ {
+			heap.PushItem(i)
+		}
+
+		sum := 0
+		for i := 0; i < 1000; i++ {
+			sum += heap.PopMax().(int)
+		}
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleHeap() {
+	const K = 3
+	heap := minmaxheap.New(lessInt)
+
+	// keep track of the K largest items
+	for _, item := range []int{7, 2, 9, 4, 11, 5, 1, 8} {
+		heap.PushItem(item)
+		if heap.Size() > K {
+			heap.PopMin()
+		}
+	}
+
+	for heap.Size() > 0 {
+		fmt.Printf("%v ", heap.PopMax())
+	}
+	fmt.Println()
+
+	// Output:
+	// 11 9 8
+}
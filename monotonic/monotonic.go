@@ -0,0 +1,104 @@
+// monotonic.go, jpad 2026
+
+/*
+Package monotonic implements a sliding window over a stream of integers that
+answers minimum and maximum queries in amortized constant time.
+
+Every pushed item is stamped with its position in the stream. Items leave the
+window when they are evicted by position, so the window can slide by count
+or, with a little bookkeeping by the caller, by time.
+*/
+package monotonic
+
+import (
+	"github.com/notnot/container/deque_int"
+)
+
+//// Window ////////////////////////////////////////////////////////////////////
+
+// Window is a sliding window over a stream of integers.
+type Window struct {
+	minV  *deque_int.Deque // ascending candidates for the minimum
+	minP  *deque_int.Deque // stream positions of minV
+	maxV  *deque_int.Deque // descending candidates for the maximum
+	maxP  *deque_int.Deque // stream positions of maxV
+	first int              // position of the oldest item in the window
+	next  int              // position of the next pushed item
+}
+
+// New returns a pointer to an empty window.
+func New() *Window {
+	return &Window{
+		minV: deque_int.New(),
+		minP: deque_int.New(),
+		maxV: deque_int.New(),
+		maxP: deque_int.New(),
+	}
+}
+
+// Push adds an item to the window and returns its stream position. Positions
+// start at 0 and increase by one with every push.
+func (w *Window) Push(item int) int {
+	pos := w.next
+	w.next++
+
+	// items that can never become the minimum or maximum again are dropped
+	for w.minV.Size() > 0 && w.minV.BackItem() >= item {
+		w.minV.PopBack()
+		w.minP.PopBack()
+	}
+	w.minV.PushBack(item)
+	w.minP.PushBack(pos)
+
+	for w.maxV.Size() > 0 && w.maxV.BackItem() <= item {
+		w.maxV.PopBack()
+		w.maxP.PopBack()
+	}
+	w.maxV.PushBack(item)
+	w.maxP.PushBack(pos)
+
+	return pos
+}
+
+// Evict removes all items with a stream position lower than olderThan from
+// the window.
+func (w *Window) Evict(olderThan int) {
+	if olderThan > w.next {
+		olderThan = w.next
+	}
+	if olderThan <= w.first {
+		return
+	}
+	w.first = olderThan
+
+	for w.minP.Size() > 0 && w.minP.FrontItem() < olderThan {
+		w.minV.PopFront()
+		w.minP.PopFront()
+	}
+	for w.maxP.Size() > 0 && w.maxP.FrontItem() < olderThan {
+		w.maxV.PopFront()
+		w.maxP.PopFront()
+	}
+}
+
+// Min returns the smallest item in the window.
+// Returns the zero value when the window is empty.
+func (w *Window) Min() int {
+	return w.minV.FrontItem()
+}
+
+// Max returns the largest item in the window.
+// Returns the zero value when the window is empty.
+func (w *Window) Max() int {
+	return w.maxV.FrontItem()
+}
+
+// Size returns the number of items in the window.
+func (w *Window) Size() int {
+	return w.next - w.first
+}
+
+// Clear removes all items from the window. Stream positions keep counting.
+func (w *Window) Clear() {
+	w.Evict(w.next)
+}
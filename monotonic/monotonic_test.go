@@ -0,0 +1,123 @@
+// monotonic_test.go, jpad 2026
+
+package monotonic_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/notnot/container/monotonic"
+)
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestEmpty(t *testing.T) {
+	window := monotonic.New()
+
+	if window.Size() != 0 {
+		t.Errorf("got: %d, want: 0", window.Size())
+	}
+	if window.Min() != 0 {
+		t.Errorf("got: %d, want: 0", window.Min())
+	}
+	if window.Max() != 0 {
+		t.Errorf("got: %d, want: 0", window.Max())
+	}
+}
+
+func TestPushEvict(t *testing.T) {
+	window := monotonic.New()
+
+	for i, item := range []int{5, 3, 8, 3, 1, 9} {
+		pos := window.Push(item)
+		if pos != i {
+			t.Errorf("got: %d, want: %d", pos, i)
+		}
+	}
+	if window.Min() != 1 || window.Max() != 9 {
+		t.Errorf("got: %d..%d, want: 1..9", window.Min(), window.Max())
+	}
+
+	window.Evict(5) // keep only 9
+	if window.Min() != 9 || window.Max() != 9 {
+		t.Errorf("got: %d..%d, want: 9..9", window.Min(), window.Max())
+	}
+	if window.Size() != 1 {
+		t.Errorf("got: %d, want: 1", window.Size())
+	}
+
+	window.Evict(100)
+	if window.Size() != 0 {
+		t.Errorf("got: %d, want: 0", window.Size())
+	}
+	if pos := window.Push(4); pos != 6 {
+		t.Errorf("got: %d, want: 6", pos)
+	}
+}
+
+func TestSlidingRandom(t *testing.T) {
+	const (
+		N = 10000
+		W = 50
+	)
+	window := monotonic.New()
+	stream := make([]int, 0, N)
+
+	for i := 0; i < N; i++ {
+		item := rand.Intn(1000) - 500
+		stream = append(stream, item)
+		window.Push(item)
+		window.Evict(i - W + 1)
+
+		lo := i - W + 1
+		if lo < 0 {
+			lo = 0
+		}
+		min, max := stream[lo], stream[lo]
+		for _, v := range stream[lo:] {
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if window.Min() != min {
+			t.Fatalf("%d: got: %d, want: %d", i, window.Min(), min)
+		}
+		if window.Max() != max {
+			t.Fatalf("%d: got: %d, want: %d", i, window.Max(), max)
+		}
+		if window.Size() != i-lo+1 {
+			t.Fatalf("%d: got: %d, want: %d", i, window.Size(), i-lo+1)
+		}
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkPushEvict_100(b *testing.B) {
+	window := monotonic.New()
+	for i := 0; i < b.N; i++ {
+		pos := window.Push(rand.Int())
+		window.Evict(pos - 100)
+		_ = window.Min()
+		_ = window.Max()
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleWindow() {
+	window := monotonic.New()
+	for _, item := range []int{4, 2, 12, 3, 8, 7, 1} {
+		pos := window.Push(item)
+		window.Evict(pos - 2) // keep the last 3 items
+		fmt.Printf("%d..%d ", window.Min(), window.Max())
+	}
+	fmt.Println()
+
+	// Output:
+	// 4..4 2..4 2..12 2..12 3..12 3..8 1..8
+}
@@ -0,0 +1,36 @@
+// clock.go, jpad 2026
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+//// FakeClock /////////////////////////////////////////////////////////////////
+
+// FakeClock is a clock that only moves when it is advanced, for testing code
+// that uses a limiter. Pass its Now method to a limiter constructor.
+type FakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewFakeClock returns a pointer to a fake clock set to time t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{t: t}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
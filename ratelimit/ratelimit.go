@@ -0,0 +1,44 @@
+// ratelimit.go, jpad 2026
+
+/*
+Package ratelimit implements rate limiters that admit at most a given number of
+events per sliding window of time.
+
+SlidingLog remembers the time of every admitted event in a deque_int of unix
+nanoseconds, and is exact. SlidingWindow only counts the events per fixed
+window, and estimates the rate over the sliding window by weighting the count
+of the previous fixed window; it uses constant memory per window.
+
+Both limiters are safe for concurrent use. The clock is injectable, see
+FakeClock.
+*/
+package ratelimit
+
+import (
+	"time"
+)
+
+// Limiter is the interface shared by the rate limiters of this package.
+type Limiter interface {
+	// Allow reports whether an event may happen now, and if so, admits it.
+	Allow() bool
+	// AllowN reports whether n events may happen now, and if so, admits
+	// them all. Either all n events are admitted or none is.
+	AllowN(n int) bool
+	// Reserve admits an event at the earliest time the limit allows, and
+	// returns how long the caller has to wait before the event may happen.
+	// Reserved events count against the limit, so later calls to Allow fail
+	// until the reservations have come due.
+	Reserve() time.Duration
+}
+
+// checkArgs panics when a limiter is configured with a limit or window that
+// can never admit an event.
+func checkArgs(pkg string, limit int, window time.Duration) {
+	if limit < 1 {
+		panic("ratelimit: " + pkg + " limit is less than 1")
+	}
+	if window <= 0 {
+		panic("ratelimit: " + pkg + " window is not positive")
+	}
+}
@@ -0,0 +1,225 @@
+// ratelimit_test.go, jpad 2026
+
+package ratelimit_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notnot/container/ratelimit"
+)
+
+var epoch = time.Unix(1420070400, 0) // 2015-01-01, aligned to the second
+
+//// tests /////////////////////////////////////////////////////////////////////
+
+func TestSlidingLog(t *testing.T) {
+	c := ratelimit.NewFakeClock(epoch)
+	l := ratelimit.NewSlidingLog(3, time.Second, c.Now)
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow() {
+			t.Fatalf("event %d: got: denied, want: allowed", i)
+		}
+		c.Advance(100 * time.Millisecond)
+	}
+	if l.Allow() {
+		t.Errorf("got: allowed, want: denied")
+	}
+	c.Advance(700 * time.Millisecond) // 1s: the first event has left
+	if !l.Allow() || l.Allow() {
+		t.Errorf("got: wrong admission at 1s, want: exactly one")
+	}
+	if l.Size() != 3 {
+		t.Errorf("got: %d, want: 3", l.Size())
+	}
+}
+
+func TestSlidingLog_AllowN(t *testing.T) {
+	c := ratelimit.NewFakeClock(epoch)
+	l := ratelimit.NewSlidingLog(5, time.Second, c.Now)
+
+	if l.AllowN(6) {
+		t.Errorf("got: 6 allowed, want: denied")
+	}
+	if !l.AllowN(3) {
+		t.Errorf("got: 3 denied, want: allowed")
+	}
+	if l.AllowN(3) {
+		t.Errorf("got: 3 more allowed, want: denied")
+	}
+	if !l.AllowN(2) {
+		t.Errorf("got: 2 more denied, want: allowed")
+	}
+	c.Advance(time.Second)
+	if !l.AllowN(5) {
+		t.Errorf("got: 5 denied after a window, want: allowed")
+	}
+}
+
+func TestSlidingLog_Reserve(t *testing.T) {
+	c := ratelimit.NewFakeClock(epoch)
+	l := ratelimit.NewSlidingLog(2, time.Second, c.Now)
+
+	want := []time.Duration{0, 0, time.Second, time.Second, 2 * time.Second}
+	for i, w := range want {
+		if d := l.Reserve(); d != w {
+			t.Errorf("reservation %d: got: %v, want: %v", i, d, w)
+		}
+	}
+	c.Advance(1500 * time.Millisecond)
+	if l.Allow() {
+		t.Errorf("got: allowed, want: denied by the reservations at 1s and 2s")
+	}
+	c.Advance(time.Second) // 2.5s: only the reservation at 2s is in the window
+	if !l.Allow() || l.Allow() {
+		t.Errorf("got: wrong admission at 2.5s, want: exactly one")
+	}
+}
+
+// TestSlidingLog_model checks the limiter against a brute force count of the
+// admitted events in the window.
+func TestSlidingLog_model(t *testing.T) {
+	const (
+		limit  = 40 // spans more than one deque chunk
+		window = time.Second
+	)
+	c := ratelimit.NewFakeClock(epoch)
+	l := ratelimit.NewSlidingLog(limit, window, c.Now)
+	admitted := []time.Time{}
+
+	for i := 0; i < 5000; i++ {
+		c.Advance(time.Duration(rand.Intn(50)) * time.Millisecond)
+		now := c.Now()
+		n := 1 + rand.Intn(3)
+
+		inWindow := 0
+		for _, at := range admitted {
+			if now.Sub(at) < window {
+				inWindow++
+			}
+		}
+		want := inWindow+n <= limit
+		if got := l.AllowN(n); got != want {
+			t.Fatalf("step %d: got: %v, want: %v", i, got, want)
+		}
+		if want {
+			for j := 0; j < n; j++ {
+				admitted = append(admitted, now)
+			}
+		}
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	c := ratelimit.NewFakeClock(epoch)
+	w := ratelimit.NewSlidingWindow(10, time.Second, c.Now)
+
+	if !w.AllowN(10) || w.Allow() {
+		t.Fatalf("got: wrong admission in the first window, want: 10")
+	}
+	c.Advance(time.Second) // previous window counts in full
+	if w.Allow() {
+		t.Errorf("got: allowed, want: denied")
+	}
+	c.Advance(500 * time.Millisecond) // previous window counts half
+	if !w.AllowN(5) || w.Allow() {
+		t.Errorf("got: wrong admission at 1.5s, want: 5")
+	}
+	// 10*(1-f) + 5 + 1 <= 10 when f >= 0.6
+	if d := w.Reserve(); d != 100*time.Millisecond {
+		t.Errorf("got: %v, want: 100ms", d)
+	}
+	c.Advance(2 * time.Second)
+	if !w.AllowN(10) {
+		t.Errorf("got: denied after idle windows, want: allowed")
+	}
+	if w.AllowN(11) {
+		t.Errorf("got: 11 allowed, want: denied")
+	}
+}
+
+func TestSlidingWindow_Reserve(t *testing.T) {
+	c := ratelimit.NewFakeClock(epoch)
+	w := ratelimit.NewSlidingWindow(4, time.Second, c.Now)
+
+	// the first window fills at once, then each quarter of the next window
+	// frees one event
+	want := []time.Duration{0, 0, 0, 0, 1250, 1500, 1750, 2000}
+	for i, ms := range want {
+		if d := w.Reserve(); d != ms*time.Millisecond {
+			t.Errorf("reservation %d: got: %v, want: %v", i, d, ms*time.Millisecond)
+		}
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	const limit = 100
+	limiters := map[string]ratelimit.Limiter{
+		"SlidingLog":    ratelimit.NewSlidingLog(limit, time.Hour, nil),
+		"SlidingWindow": ratelimit.NewSlidingWindow(limit, time.Hour, nil),
+	}
+	for name, l := range limiters {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					if l.Allow() {
+						mu.Lock()
+						allowed++
+						mu.Unlock()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		// a window boundary may pass, but never more than once
+		if allowed < limit || allowed > 2*limit {
+			t.Errorf("%s: got: %d, want: %d", name, allowed, limit)
+		}
+	}
+}
+
+//// benchmarks ////////////////////////////////////////////////////////////////
+
+func BenchmarkSlidingLog(b *testing.B) {
+	c := ratelimit.NewFakeClock(epoch)
+	l := ratelimit.NewSlidingLog(1000, time.Second, c.Now)
+	for i := 0; i < b.N; i++ {
+		c.Advance(time.Millisecond)
+		l.Allow()
+	}
+}
+
+func BenchmarkSlidingWindow(b *testing.B) {
+	c := ratelimit.NewFakeClock(epoch)
+	w := ratelimit.NewSlidingWindow(1000, time.Second, c.Now)
+	for i := 0; i < b.N; i++ {
+		c.Advance(time.Millisecond)
+		w.Allow()
+	}
+}
+
+//// examples //////////////////////////////////////////////////////////////////
+
+func ExampleSlidingLog() {
+	c := ratelimit.NewFakeClock(epoch)
+	l := ratelimit.NewSlidingLog(2, time.Minute, c.Now)
+
+	fmt.Println(l.Allow(), l.Allow(), l.Allow())
+	fmt.Println(l.Reserve())
+	c.Advance(time.Minute)
+	fmt.Println(l.Allow(), l.Allow()) // the reserved event takes one slot
+
+	// Output:
+	// true true false
+	// 1m0s
+	// true false
+}
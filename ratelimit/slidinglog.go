@@ -0,0 +1,127 @@
+// slidinglog.go, jpad 2026
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/notnot/container/deque_int"
+)
+
+//// SlidingLog ////////////////////////////////////////////////////////////////
+
+// SlidingLog is an exact rate limiter that admits at most limit events in any
+// window of time. It logs the time of every admitted event, so its memory use
+// grows with the limit.
+type SlidingLog struct {
+	mu     sync.Mutex
+	log    *deque_int.Deque // unix nanos of the admitted events, ascending
+	limit  int
+	window int // in nanoseconds
+	now    func() time.Time
+}
+
+// NewSlidingLog returns a pointer to a limiter that admits at most limit
+// events per window. now is the clock of the limiter; nil selects time.Now.
+// Panics when limit is less than 1 or window is not positive.
+func NewSlidingLog(limit int, window time.Duration, now func() time.Time) *SlidingLog {
+	checkArgs("SlidingLog", limit, window)
+	if now == nil {
+		now = time.Now
+	}
+	return &SlidingLog{
+		log:    deque_int.New(),
+		limit:  limit,
+		window: int(window),
+		now:    now,
+	}
+}
+
+// Allow reports whether an event may happen now, and if so, admits it.
+func (l *SlidingLog) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, and if so, admits them all.
+func (l *SlidingLog) AllowN(n int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := int(l.now().UnixNano())
+	l.evict(now)
+	t, ok := l.earliest(now, n)
+	if !ok || t != now {
+		return false
+	}
+	l.record(t, n)
+	return true
+}
+
+// Reserve admits an event at the earliest time the limit allows, and returns
+// how long the caller has to wait before the event may happen.
+func (l *SlidingLog) Reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := int(l.now().UnixNano())
+	l.evict(now)
+	t, _ := l.earliest(now, 1) // a single event always fits eventually
+	l.record(t, 1)
+	return time.Duration(t - now)
+}
+
+// Size returns the number of logged events, including the reserved ones.
+func (l *SlidingLog) Size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.evict(int(l.now().UnixNano()))
+	return l.log.Size()
+}
+
+// evict drops the events that have left the window ending at now.
+func (l *SlidingLog) evict(now int) {
+	for l.log.Size() > 0 && l.log.FrontItem() <= now-l.window {
+		l.log.PopFront()
+	}
+}
+
+// earliest returns the earliest time, not before now, at which n events can be
+// admitted. Events are never placed before the last logged one, so the log
+// stays sorted and reservations are served in order. Returns false when n
+// exceeds the limit.
+func (l *SlidingLog) earliest(now int, n int) (int, bool) {
+	if n > l.limit {
+		return 0, false
+	}
+	t := now
+	if l.log.Size() > 0 && l.log.BackItem() > t {
+		t = l.log.BackItem()
+	}
+	// at most limit-n logged events may share a window with the new ones
+	if keep := l.limit - n; l.log.Size() > keep {
+		if due := l.latest(keep) + l.window; due > t {
+			t = due
+		}
+	}
+	return t, true
+}
+
+// latest returns the i-th latest logged time, counting from 0.
+func (l *SlidingLog) latest(i int) int {
+	for chunk := range l.log.ChunksBackward() {
+		if i < len(chunk) {
+			return chunk[len(chunk)-1-i]
+		}
+		i -= len(chunk)
+	}
+	return 0
+}
+
+// record logs n events at time t.
+func (l *SlidingLog) record(t int, n int) {
+	for ; n > 0; n-- {
+		l.log.PushBack(t)
+	}
+}
@@ -0,0 +1,152 @@
+// slidingwindow.go, jpad 2026
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/notnot/container/deque_int"
+)
+
+//// SlidingWindow /////////////////////////////////////////////////////////////
+
+// SlidingWindow is an approximate rate limiter that counts the admitted events
+// per fixed window. The rate over the sliding window is estimated as the count
+// of the current fixed window plus the count of the previous one, weighted by
+// the part of the previous window the sliding window still overlaps. The
+// estimate assumes the previous window's events were evenly spread.
+type SlidingWindow struct {
+	mu     sync.Mutex
+	counts *deque_int.Deque // admitted events per fixed window, from first on
+	first  int              // number of the fixed window at the front of counts
+	last   int              // unix nanos of the latest admitted event
+	limit  int
+	window int // in nanoseconds
+	now    func() time.Time
+}
+
+// NewSlidingWindow returns a pointer to a limiter that admits about limit
+// events per window. now is the clock of the limiter; nil selects time.Now.
+// Panics when limit is less than 1 or window is not positive.
+func NewSlidingWindow(limit int, window time.Duration, now func() time.Time) *SlidingWindow {
+	checkArgs("SlidingWindow", limit, window)
+	if now == nil {
+		now = time.Now
+	}
+	return &SlidingWindow{
+		counts: deque_int.New(),
+		limit:  limit,
+		window: int(window),
+		now:    now,
+	}
+}
+
+// Allow reports whether an event may happen now, and if so, admits it.
+func (w *SlidingWindow) Allow() bool {
+	return w.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, and if so, admits them all.
+func (w *SlidingWindow) AllowN(n int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := int(w.now().UnixNano())
+	w.evict(now)
+	t, ok := w.earliest(now, n)
+	if !ok || t != now {
+		return false
+	}
+	w.record(t, n)
+	return true
+}
+
+// Reserve admits an event at the earliest time the limit allows, and returns
+// how long the caller has to wait before the event may happen.
+func (w *SlidingWindow) Reserve() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := int(w.now().UnixNano())
+	w.evict(now)
+	t, _ := w.earliest(now, 1) // a single event always fits eventually
+	w.record(t, 1)
+	return time.Duration(t - now)
+}
+
+// evict drops the counts of the fixed windows that ended before the previous
+// one.
+func (w *SlidingWindow) evict(now int) {
+	k := now / w.window
+	for w.counts.Size() > 0 && w.first < k-1 {
+		w.counts.PopFront()
+		w.first++
+	}
+	if w.counts.Size() == 0 {
+		w.first = k - 1
+	}
+}
+
+// earliest returns the earliest time, not before now, at which n events can be
+// admitted. Like SlidingLog, events are never placed before the latest
+// admitted one. Returns false when n exceeds the limit.
+func (w *SlidingWindow) earliest(now int, n int) (int, bool) {
+	if n > w.limit {
+		return 0, false
+	}
+	t := now
+	if w.last > t {
+		t = w.last
+	}
+	for k := t / w.window; ; k++ {
+		start := k * w.window
+		if start > t {
+			t = start
+		}
+		prev, cur := w.count(k-1), w.count(k)
+		if cur+n > w.limit { // try the next fixed window
+			continue
+		}
+		if prev > 0 && prev+cur+n > w.limit {
+			// wait until the weighted count of the previous window fits
+			free := float64(w.limit-cur-n) / float64(prev)
+			due := start + w.window - int(free*float64(w.window))
+			if due > t {
+				t = due
+			}
+		}
+		if t < start+w.window {
+			return t, true
+		}
+	}
+}
+
+// count returns the number of events admitted in fixed window k.
+func (w *SlidingWindow) count(k int) int {
+	i := k - w.first
+	if i < 0 || i >= w.counts.Size() {
+		return 0
+	}
+	if i == w.counts.Size()-1 {
+		return w.counts.BackItem()
+	}
+	for chunk := range w.counts.Chunks() {
+		if i < len(chunk) {
+			return chunk[i]
+		}
+		i -= len(chunk)
+	}
+	return 0
+}
+
+// record admits n events at time t, which is never before the latest admitted
+// event, so only the back count changes.
+func (w *SlidingWindow) record(t int, n int) {
+	k := t / w.window
+	for w.first+w.counts.Size() <= k {
+		w.counts.PushBack(0)
+	}
+	w.counts.PushBack(w.counts.PopBack() + n)
+	w.last = t
+}